@@ -0,0 +1,346 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"golang.org/x/text/encoding"
+)
+
+// Errors
+var (
+	ErrFull   = errors.New("async: queue is full")
+	ErrClosed = errors.New("async: display is closed")
+)
+
+// Error is the error of the command written by the queue
+type Error struct {
+	Command string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("async: %s: %v", e.Command, e.Err)
+}
+
+// op is the queued command. The commands setting the same state
+// (e.g. the text of the row) have the same key.
+type op struct {
+	name   string
+	key    string
+	fn     func() error
+	result chan error
+}
+
+// Display queues the commands and writes them to the display by a single goroutine,
+// so the caller never waits for the device. The command returns the error
+// of the queueing; the errors of the writes are passed to the OnError handler.
+//
+// If a queued command is superseded by the command with the same key
+// (e.g. PrintRow of the same row, Brightness or CursorVisible),
+// only the last one is written at the position of the last one.
+// The command is not superseded if a command without the key
+// (e.g. Print or CursorMove) is queued after it, so the order is kept.
+type Display struct {
+	driver.Display
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*op
+	size    int
+	busy    bool
+	closed  bool
+	onError func(error)
+	done    chan struct{}
+}
+
+// New returns the display queueing up to size commands
+func New(dsp driver.Display, size int) (*Display, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("async: invalid queue size %d", size)
+	}
+	d := &Display{
+		Display: dsp,
+		size:    size,
+		done:    make(chan struct{}),
+	}
+	d.cond = sync.NewCond(&d.mu)
+	go d.run()
+	return d, nil
+}
+
+// OnError sets the handler of the errors of the writes. The handler is called
+// by the writing goroutine, so it must not call the display: the commands
+// waiting for the result (e.g. Receive or SetEncoding) would never return.
+func (d *Display) OnError(fn func(error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onError = fn
+}
+
+// Len returns the number of the queued commands
+func (d *Display) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
+
+// Cap returns the size of the queue
+func (d *Display) Cap() int {
+	return d.size
+}
+
+// Flush waits until the queued commands are written
+func (d *Display) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for len(d.queue) > 0 || d.busy {
+		d.cond.Wait()
+	}
+}
+
+func (d *Display) run() {
+	defer close(d.done)
+	for {
+		d.mu.Lock()
+		for len(d.queue) == 0 && !d.closed {
+			d.cond.Wait()
+		}
+		if len(d.queue) == 0 {
+			d.mu.Unlock()
+			return
+		}
+		o := d.queue[0]
+		d.queue = d.queue[1:]
+		d.busy = true
+		d.mu.Unlock()
+
+		err := o.fn()
+
+		d.mu.Lock()
+		d.busy = false
+		onError := d.onError
+		d.cond.Broadcast()
+		d.mu.Unlock()
+		if o.result != nil {
+			o.result <- err
+		} else if err != nil && onError != nil {
+			onError(&Error{Command: o.name, Err: err})
+		}
+	}
+}
+
+// enqueue adds the command to the queue removing the superseded one
+func (d *Display) enqueue(o *op) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	superseded := -1
+	if o.key != "" {
+		// the commands after the superseded one must have keys, so the order is kept
+		for i := len(d.queue) - 1; i >= 0 && d.queue[i].key != ""; i-- {
+			if d.queue[i].key == o.key {
+				superseded = i
+				break
+			}
+		}
+	}
+	if superseded >= 0 {
+		d.queue = append(d.queue[:superseded], d.queue[superseded+1:]...)
+	} else if o.result == nil && len(d.queue) >= d.size {
+		return ErrFull
+	}
+	d.queue = append(d.queue, o)
+	d.cond.Broadcast()
+	return nil
+}
+
+func (d *Display) post(name, key string, fn func() error) error {
+	return d.enqueue(&op{name: name, key: key, fn: fn})
+}
+
+// call queues the command and waits for its result
+func (d *Display) call(name string, fn func() error) error {
+	o := &op{name: name, fn: fn, result: make(chan error, 1)}
+	if err := d.enqueue(o); err != nil {
+		return err
+	}
+	return <-o.result
+}
+
+// Close writes the queued commands and closes the display
+func (d *Display) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrClosed
+	}
+	d.closed = true
+	d.cond.Broadcast()
+	d.mu.Unlock()
+	<-d.done
+	return d.Display.Close()
+}
+
+func (d *Display) SetEncoding(enc encoding.Encoding) {
+	d.call("SetEncoding", func() error {
+		d.Display.SetEncoding(enc)
+		return nil
+	})
+}
+
+func (d *Display) Init() error {
+	return d.post("Init", "Init", d.Display.Init)
+}
+func (d *Display) Test() error {
+	return d.post("Test", "", d.Display.Test)
+}
+func (d *Display) Clear() error {
+	return d.post("Clear", "Clear", d.Display.Clear)
+}
+
+func (d *Display) Send(data []byte) error {
+	data = append([]byte(nil), data...)
+	return d.post("Send", "", func() error { return d.Display.Send(data) })
+}
+
+// Receive waits for the queued commands and reads from the display
+func (d *Display) Receive(b []byte) (n int, err error) {
+	err = d.call("Receive", func() error {
+		n, err = d.Display.Receive(b)
+		return err
+	})
+	return n, err
+}
+
+func (d *Display) ModeRewrite() error {
+	return d.post("ModeRewrite", "Mode", d.Display.ModeRewrite)
+}
+func (d *Display) ModeVScroll() error {
+	return d.post("ModeVScroll", "Mode", d.Display.ModeVScroll)
+}
+func (d *Display) ModeHScroll() error {
+	return d.post("ModeHScroll", "Mode", d.Display.ModeHScroll)
+}
+func (d *Display) Brightness(value byte) error {
+	return d.post("Brightness", "Brightness", func() error { return d.Display.Brightness(value) })
+}
+
+func (d *Display) ClearRow() error {
+	return d.post("ClearRow", "", d.Display.ClearRow)
+}
+
+func (d *Display) CursorVisible(visible bool) error {
+	return d.post("CursorVisible", "CursorVisible", func() error { return d.Display.CursorVisible(visible) })
+}
+func (d *Display) CursorMoveUp() error {
+	return d.post("CursorMoveUp", "", d.Display.CursorMoveUp)
+}
+func (d *Display) CursorMoveDown() error {
+	return d.post("CursorMoveDown", "", d.Display.CursorMoveDown)
+}
+func (d *Display) CursorMoveRight() error {
+	return d.post("CursorMoveRight", "", d.Display.CursorMoveRight)
+}
+func (d *Display) CursorMoveLeft() error {
+	return d.post("CursorMoveLeft", "", d.Display.CursorMoveLeft)
+}
+func (d *Display) CursorMoveLeftTop() error {
+	return d.post("CursorMoveLeftTop", "", d.Display.CursorMoveLeftTop)
+}
+func (d *Display) CursorMoveBeginInRow() error {
+	return d.post("CursorMoveBeginInRow", "", d.Display.CursorMoveBeginInRow)
+}
+func (d *Display) CursorMoveEndInRow() error {
+	return d.post("CursorMoveEndInRow", "", d.Display.CursorMoveEndInRow)
+}
+func (d *Display) CursorMoveBottom() error {
+	return d.post("CursorMoveBottom", "", d.Display.CursorMoveBottom)
+}
+func (d *Display) CursorMove(row, col byte) error {
+	return d.post("CursorMove", "", func() error { return d.Display.CursorMove(row, col) })
+}
+
+func (d *Display) PrintRow(row byte, text string) error {
+	return d.post("PrintRow", fmt.Sprintf("PrintRow:%d", row), func() error { return d.Display.PrintRow(row, text) })
+}
+func (d *Display) Print(text string) error {
+	return d.post("Print", "", func() error { return d.Display.Print(text) })
+}
+
+func (d *Display) CharSize(width, height byte) error {
+	return d.post("CharSize", "", func() error { return d.Display.CharSize(width, height) })
+}
+
+func (d *Display) FlagEnable(enabled bool, num byte) error {
+	return d.post("FlagEnable", fmt.Sprintf("FlagEnable:%d", num), func() error { return d.Display.FlagEnable(enabled, num) })
+}
+func (d *Display) FlagsDisable() error {
+	return d.post("FlagsDisable", "", d.Display.FlagsDisable)
+}
+
+func (d *Display) SetTime(t time.Time) error {
+	return d.post("SetTime", "SetTime", func() error { return d.Display.SetTime(t) })
+}
+func (d *Display) ShowClock() error {
+	return d.post("ShowClock", "", d.Display.ShowClock)
+}
+
+func (d *Display) Blink(interval time.Duration) error {
+	return d.post("Blink", "Blink", func() error { return d.Display.Blink(interval) })
+}
+func (d *Display) BlinkRow(row byte, interval time.Duration) error {
+	return d.post("BlinkRow", fmt.Sprintf("BlinkRow:%d", row), func() error { return d.Display.BlinkRow(row, interval) })
+}
+
+// The attributes, the character set and the user-defined characters
+// change the following text and are never coalesced.
+
+func (d *Display) Reverse(enabled bool) error {
+	return d.post("Reverse", "", func() error { return d.Display.Reverse(enabled) })
+}
+func (d *Display) Underline(enabled bool) error {
+	return d.post("Underline", "", func() error { return d.Display.Underline(enabled) })
+}
+
+func (d *Display) SetCodeTable(table byte) error {
+	return d.post("SetCodeTable", "", func() error { return d.Display.SetCodeTable(table) })
+}
+func (d *Display) SetCharset(charset byte) error {
+	return d.post("SetCharset", "", func() error { return d.Display.SetCharset(charset) })
+}
+
+func (d *Display) DefineChar(code byte, glyph []byte) error {
+	glyph = append([]byte(nil), glyph...)
+	return d.post("DefineChar", "", func() error { return d.Display.DefineChar(code, glyph) })
+}
+func (d *Display) UserChars(enabled bool) error {
+	return d.post("UserChars", "", func() error { return d.Display.UserChars(enabled) })
+}
+
+// Status waits for the queued commands and requests the status
+func (d *Display) Status() (status driver.Status, err error) {
+	err = d.call("Status", func() error {
+		status, err = d.Display.Status()
+		return err
+	})
+	return status, err
+}
+
+// AddObserver adds the observer to the display if it reports the events.
+// The observer is called by the writing goroutine.
+func (d *Display) AddObserver(o driver.Observer) {
+	if observable, ok := d.Display.(driver.Observable); ok {
+		observable.AddObserver(o)
+	}
+}
+
+// TextWidth returns the number of the cells of the text on the wrapped display
+func (d *Display) TextWidth(text string) int {
+	return driver.TextWidth(d.Display, text)
+}
@@ -0,0 +1,164 @@
+package async
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockDisplay struct {
+	driver.Display
+	mu      sync.Mutex
+	written []string
+	block   chan struct{}
+	err     error
+}
+
+func (m *mockDisplay) log(cmd string) error {
+	if m.block != nil {
+		<-m.block
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.written = append(m.written, cmd)
+	return m.err
+}
+
+func (m *mockDisplay) PrintRow(row byte, text string) error {
+	return m.log(text)
+}
+
+func (m *mockDisplay) Clear() error {
+	return m.log("clear")
+}
+
+func (m *mockDisplay) Receive(b []byte) (int, error) {
+	return copy(b, "ok"), m.log("receive")
+}
+
+func (m *mockDisplay) Print(text string) error {
+	return m.log("print " + text)
+}
+
+func (m *mockDisplay) CursorMove(row, col byte) error {
+	return m.log("move")
+}
+
+func (m *mockDisplay) ModeVScroll() error {
+	return m.log("vscroll")
+}
+
+func (m *mockDisplay) ModeRewrite() error {
+	return m.log("rewrite")
+}
+
+func (m *mockDisplay) Close() error {
+	return nil
+}
+
+func TestCoalesce(t *testing.T) {
+	block := make(chan struct{})
+	dsp := &mockDisplay{block: block}
+	d, err := New(dsp, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// the first command is written while the others are queued
+	if err := d.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	for d.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, text := range []string{"a", "b", "c"} {
+		if err := d.PrintRow(1, text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.PrintRow(2, "x")
+	d.PrintRow(1, "d")
+	if got := d.Len(); got != 2 {
+		t.Errorf("Excepted queue depth 2, got %d", got)
+	}
+	d.Clear()
+	if err := d.PrintRow(3, "y"); err != ErrFull {
+		t.Errorf("Excepted %v, got %v", ErrFull, err)
+	}
+	// the superseding command is queued into the full queue
+	if err := d.PrintRow(2, "z"); err != nil {
+		t.Errorf("Excepted superseding command queued, got %v", err)
+	}
+	close(block)
+	d.Flush()
+
+	want := []string{"clear", "d", "clear", "z"}
+	if !reflect.DeepEqual(dsp.written, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.written)
+	}
+}
+
+func TestCoalesceKeepsOrder(t *testing.T) {
+	block := make(chan struct{})
+	dsp := &mockDisplay{block: block}
+	d, err := New(dsp, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	d.PrintRow(1, "a")
+	d.CursorMove(2, 1)
+	d.Print("x")
+	d.PrintRow(1, "b")
+	d.ModeVScroll()
+	d.Print("y")
+	d.ModeRewrite()
+	close(block)
+	d.Flush()
+
+	want := []string{"a", "move", "print x", "b", "vscroll", "print y", "rewrite"}
+	if !reflect.DeepEqual(dsp.written, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.written)
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	if _, err := New(&mockDisplay{}, 0); err == nil || err.Error() != "async: invalid queue size 0" {
+		t.Errorf("Excepted error of the size, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dsp := &mockDisplay{err: errors.New("fake error")}
+	d, err := New(dsp, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []error
+	d.OnError(func(err error) {
+		got = append(got, err)
+	})
+	if err := d.PrintRow(1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 2)
+	if _, err := d.Receive(b); err == nil || err.Error() != "fake error" {
+		t.Errorf("Excepted error of Receive, got %v", err)
+	}
+	if len(got) != 1 || got[0].Error() != "async: PrintRow: fake error" {
+		t.Errorf("Excepted error of PrintRow, got %v", got)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Clear(); err != ErrClosed {
+		t.Errorf("Excepted %v, got %v", ErrClosed, err)
+	}
+}
@@ -0,0 +1,105 @@
+package bignum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/glyph"
+)
+
+// Pieces of the big digits. They are the private use runes registered
+// in the glyph library and shown by the user-defined characters.
+const (
+	Full rune = 0xe000 + iota
+	Upper
+	Lower
+	UpperLower
+)
+
+func init() {
+	glyph.Register(Full, glyph.FromRows("#####", "#####", "#####", "#####", "#####", "#####", "#####"))
+	glyph.Register(Upper, glyph.FromRows("#####", "#####"))
+	glyph.Register(Lower, glyph.FromRows("", "", "", "", "", "#####", "#####"))
+	glyph.Register(UpperLower, glyph.FromRows("#####", "#####", "", "", "", "#####", "#####"))
+}
+
+const (
+	f = Full
+	u = Upper
+	l = Lower
+	m = UpperLower
+)
+
+// digits are the upper and the lower rows of the big characters
+var digits = map[rune][2][]rune{
+	'0': {{f, u, f}, {f, l, f}},
+	'1': {{u, f, ' '}, {l, f, l}},
+	'2': {{m, m, f}, {f, l, l}},
+	'3': {{m, m, f}, {l, l, f}},
+	'4': {{f, l, f}, {' ', ' ', f}},
+	'5': {{f, m, m}, {l, l, f}},
+	'6': {{f, m, m}, {f, l, f}},
+	'7': {{u, u, f}, {' ', ' ', f}},
+	'8': {{f, m, f}, {f, l, f}},
+	'9': {{f, m, f}, {l, l, f}},
+	'-': {{l, l}, {' ', ' '}},
+	' ': {{' '}, {' '}},
+}
+
+// Render returns the two rows of the big text aligned to the right of the width.
+// The digits are 3 columns wide, the adjacent big characters are separated
+// by a blank column, other characters are written in the lower row.
+func Render(text string, width int) ([2]string, error) {
+	var upper, lower []rune
+	big := false
+	for _, r := range text {
+		if d, ok := digits[r]; ok && r != ' ' {
+			if big {
+				upper = append(upper, ' ')
+				lower = append(lower, ' ')
+			}
+			upper = append(upper, d[0]...)
+			lower = append(lower, d[1]...)
+			big = true
+			continue
+		}
+		big = false
+		upper = append(upper, ' ')
+		lower = append(lower, r)
+	}
+	if len(upper) > width {
+		return [2]string{}, fmt.Errorf("bignum: %q does not fit in %d columns", text, width)
+	}
+	pad := strings.Repeat(" ", width-len(upper))
+	return [2]string{pad + string(upper), pad + string(lower)}, nil
+}
+
+// Show prints the text in the double size characters in the first row.
+// If the display has no double size, the big digits are printed in the rows 1 and 2
+// with the user-defined characters. The display must substitute at least
+// 4 runes of the glyph library (see com.Serial.SetGlyphSlots).
+func Show(dsp driver.Display, text string, width int) error {
+	err := dsp.CharSize(2, 2)
+	if err == nil {
+		err = dsp.PrintRow(1, text)
+		if e := dsp.CharSize(1, 1); err == nil {
+			err = e
+		}
+		return err
+	}
+	if !errors.Is(err, driver.ErrNotSupported) {
+		return err
+	}
+	rows, err := Render(text, width)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if err := dsp.PrintRow(byte(i+1), row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
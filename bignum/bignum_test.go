@@ -0,0 +1,91 @@
+package bignum
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/glyph"
+)
+
+type mockDisplay struct {
+	driver.Display
+	rows       map[byte]string
+	sizes      [][2]byte
+	CharSizeFn func(width, height byte) error
+}
+
+func (m *mockDisplay) PrintRow(row byte, text string) error {
+	m.rows[row] = text
+	return nil
+}
+
+func (m *mockDisplay) CharSize(width, height byte) error {
+	m.sizes = append(m.sizes, [2]byte{width, height})
+	return m.CharSizeFn(width, height)
+}
+
+func TestRender(t *testing.T) {
+	got, err := Render("10.5", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [2]string{
+		string([]rune{' ', u, f, ' ', ' ', f, u, f, ' ', f, m, m}),
+		string([]rune{' ', l, f, l, ' ', f, l, f, '.', l, l, f}),
+	}
+	if got != want {
+		t.Errorf("Excepted %+q, got %+q", want, got)
+	}
+	// the adjacent digits are separated
+	got, err = Render("-00", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = [2]string{
+		string([]rune{l, l, ' ', f, u, f, ' ', f, u, f}),
+		string([]rune{' ', ' ', ' ', f, l, f, ' ', f, l, f}),
+	}
+	if got != want {
+		t.Errorf("Excepted %+q, got %+q", want, got)
+	}
+	if _, err := Render("12345", 10); err == nil {
+		t.Error("Excepted error of too long text")
+	}
+	for _, r := range []rune{Full, Upper, Lower, UpperLower} {
+		if _, ok := glyph.Lookup(r); !ok {
+			t.Errorf("Excepted glyph of %U", r)
+		}
+	}
+}
+
+func TestShow(t *testing.T) {
+	dsp := &mockDisplay{
+		rows:       make(map[byte]string),
+		CharSizeFn: func(width, height byte) error { return nil },
+	}
+	if err := Show(dsp, "20$", 20); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[byte]string{1: "20$"}; !reflect.DeepEqual(dsp.rows, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.rows)
+	}
+	if want := [][2]byte{{2, 2}, {1, 1}}; !reflect.DeepEqual(dsp.sizes, want) {
+		t.Errorf("Excepted sizes %v, got %v", want, dsp.sizes)
+	}
+
+	dsp = &mockDisplay{
+		rows:       make(map[byte]string),
+		CharSizeFn: func(width, height byte) error { return driver.ErrNotSupported },
+	}
+	if err := Show(dsp, "7", 4); err != nil {
+		t.Fatal(err)
+	}
+	want := map[byte]string{
+		1: " " + string([]rune{u, u, f}),
+		2: "   " + string(f),
+	}
+	if !reflect.DeepEqual(dsp.rows, want) {
+		t.Errorf("Excepted %+q, got %+q", want, dsp.rows)
+	}
+}
@@ -0,0 +1,161 @@
+package clock
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// Default layouts of the software clock
+const (
+	DefaultTimeLayout = "15:04"
+	DefaultDateLayout = "02.01.2006"
+)
+
+// Clock shows the current time on a line display. It uses the built-in
+// clock of the device when the protocol supports it, otherwise renders
+// the time with PrintRow on every tick.
+type Clock struct {
+	mu       sync.Mutex
+	dsp      driver.Display
+	layouts  map[byte]string
+	interval time.Duration
+	now      func() time.Time
+	onError  func(error)
+
+	stop    chan struct{}
+	done    chan struct{}
+	printed map[byte]string
+}
+
+// New returns a clock for the display with the time in the first row
+// and the date in the second one.
+func New(dsp driver.Display) *Clock {
+	return &Clock{
+		dsp: dsp,
+		layouts: map[byte]string{
+			1: DefaultTimeLayout,
+			2: DefaultDateLayout,
+		},
+		interval: time.Second,
+		now:      time.Now,
+	}
+}
+
+// SetLayout sets the time layout (see time.Format) of the row.
+// An empty layout removes the row from the software clock.
+func (c *Clock) SetLayout(row byte, layout string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if layout == "" {
+		delete(c.layouts, row)
+		return
+	}
+	c.layouts[row] = layout
+}
+
+// SetInterval sets how often the software clock checks the time
+func (c *Clock) SetInterval(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d > 0 {
+		c.interval = d
+	}
+}
+
+// OnError sets the handler of the errors of the software clock ticks
+func (c *Clock) OnError(fn func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onError = fn
+}
+
+// Start shows the hardware clock of the device. If the protocol has no clock
+// commands, the software clock is started instead. The running software clock
+// is stopped before.
+func (c *Clock) Start() error {
+	c.Stop()
+	err := c.dsp.SetTime(c.now())
+	if err == nil {
+		err = c.dsp.ShowClock()
+	}
+	if errors.Is(err, driver.ErrNotSupported) {
+		return c.StartSoftware()
+	}
+	return err
+}
+
+// StartSoftware renders the time with PrintRow until Stop is called
+func (c *Clock) StartSoftware() error {
+	c.Stop()
+	c.mu.Lock()
+	c.printed = make(map[byte]string)
+	err := c.render()
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	c.stop, c.done = stop, done
+	interval := c.interval
+	c.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.mu.Lock()
+				err := c.render()
+				onError := c.onError
+				c.mu.Unlock()
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the software clock. The content of the display is left as is.
+func (c *Clock) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// render prints the rows which text has changed since the last tick
+func (c *Clock) render() error {
+	now := c.now()
+	rows := make([]int, 0, len(c.layouts))
+	for row := range c.layouts {
+		rows = append(rows, int(row))
+	}
+	sort.Ints(rows)
+	for _, r := range rows {
+		row := byte(r)
+		text := now.Format(c.layouts[row])
+		if c.printed[row] == text {
+			continue
+		}
+		if err := c.dsp.PrintRow(row, text); err != nil {
+			return err
+		}
+		c.printed[row] = text
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package clock
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockDisplay struct {
+	driver.Display
+
+	mu          sync.Mutex
+	rows        map[byte]string
+	prints      int
+	printErr    error
+	SetTimeFn   func(time.Time) error
+	ShowClockFn func() error
+}
+
+func (m *mockDisplay) PrintRow(row byte, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.printErr != nil {
+		return m.printErr
+	}
+	m.rows[row] = text
+	m.prints++
+	return nil
+}
+
+func (m *mockDisplay) SetTime(t time.Time) error {
+	return m.SetTimeFn(t)
+}
+
+func (m *mockDisplay) ShowClock() error {
+	return m.ShowClockFn()
+}
+
+func TestHardwareClock(t *testing.T) {
+	var settime time.Time
+	shown := false
+	dsp := &mockDisplay{
+		rows: make(map[byte]string),
+		SetTimeFn: func(t time.Time) error {
+			settime = t
+			return nil
+		},
+		ShowClockFn: func() error {
+			shown = true
+			return nil
+		},
+	}
+	now := time.Date(2017, 5, 1, 10, 30, 0, 0, time.UTC)
+	c := New(dsp)
+	c.now = func() time.Time { return now }
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+	if !settime.Equal(now) || !shown {
+		t.Errorf("Excepted SetTime(%v) and ShowClock() to be invoked, got %v,%v", now, settime, shown)
+	}
+	if dsp.prints != 0 {
+		t.Errorf("Excepted no PrintRow, got %d", dsp.prints)
+	}
+}
+
+func TestSoftwareClock(t *testing.T) {
+	dsp := &mockDisplay{
+		rows: make(map[byte]string),
+		SetTimeFn: func(time.Time) error {
+			return driver.ErrNotSupported
+		},
+	}
+	now := time.Date(2017, 5, 1, 10, 30, 0, 0, time.UTC)
+	c := New(dsp)
+	c.now = func() time.Time { return now }
+	c.SetLayout(2, "Mon 02 Jan")
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+	if got := dsp.rows[1]; got != "10:30" {
+		t.Errorf("Excepted row 1 %q, got %q", "10:30", got)
+	}
+	if got := dsp.rows[2]; got != "Mon 01 May" {
+		t.Errorf("Excepted row 2 %q, got %q", "Mon 01 May", got)
+	}
+
+	c.SetLayout(2, "")
+	c.SetInterval(time.Millisecond)
+	dsp.prints = 0
+	if err := c.StartSoftware(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	c.Stop()
+	dsp.mu.Lock()
+	defer dsp.mu.Unlock()
+	if dsp.prints != 1 {
+		t.Errorf("Excepted unchanged time to be printed once, got %d", dsp.prints)
+	}
+}
+
+func TestStartStopsSoftwareClock(t *testing.T) {
+	supported := false
+	dsp := &mockDisplay{
+		rows: make(map[byte]string),
+		SetTimeFn: func(time.Time) error {
+			if !supported {
+				return driver.ErrNotSupported
+			}
+			return nil
+		},
+		ShowClockFn: func() error {
+			return nil
+		},
+	}
+	c := New(dsp)
+	c.SetInterval(time.Millisecond)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	supported = true
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		t.Errorf("Excepted the software clock to be stopped by the hardware clock")
+	}
+}
+
+func TestSoftwareClockError(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	var mu sync.Mutex
+	now := time.Date(2017, 5, 1, 10, 30, 0, 0, time.UTC)
+	c := New(dsp)
+	c.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Minute)
+		return now
+	}
+	c.SetInterval(time.Millisecond)
+	errs := make(chan error, 1)
+	c.OnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	if err := c.StartSoftware(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	errPrint := errors.New("print failed")
+	dsp.mu.Lock()
+	dsp.printErr = errPrint
+	dsp.mu.Unlock()
+	select {
+	case err := <-errs:
+		if err != errPrint {
+			t.Errorf("Excepted %v, got %v", errPrint, err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Excepted the error of the tick to be handled")
+	}
+}
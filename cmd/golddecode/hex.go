@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// parseHex parses the hex dump. The bytes are the pairs of the hex digits
+// separated by the spaces or the commas with the optional 0x prefix.
+// The offsets and the text columns of hexdump -C and xxd are skipped.
+func parseHex(text string) ([]byte, error) {
+	var digits strings.Builder
+	hexdump := strings.Contains(text, "|")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if hexdump {
+			// hexdump -C: offset, bytes, |text| and the last line is the offset
+			if i := strings.IndexByte(line, '|'); i >= 0 {
+				line = line[:i]
+			}
+			if fields := strings.Fields(line); len(fields) > 0 {
+				line = strings.Join(fields[1:], " ")
+			}
+		} else if i := strings.Index(line, ": "); i >= 0 && isHex(line[:i]) {
+			// xxd: offset: bytes  text
+			line = line[i+2:]
+			if j := strings.Index(line, "  "); j >= 0 {
+				line = line[:j]
+			}
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == ','
+		}) {
+			field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+			if len(field)%2 != 0 {
+				return nil, errors.New("odd number of hex digits in " + field)
+			}
+			digits.WriteString(field)
+		}
+	}
+	if digits.Len() == 0 {
+		return nil, errors.New("no hex digits")
+	}
+	return hex.DecodeString(digits.String())
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := hex.DecodeString(strings.Repeat("0", len(s)%2) + s)
+	return err == nil
+}
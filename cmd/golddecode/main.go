@@ -0,0 +1,158 @@
+// Command golddecode turns the dumps of the serial traffic to the display
+// back into the commands:
+//
+//	$ echo "1b 51 41 50 72 69 63 65 0d 1b 2a 03" | golddecode
+//	 000000  PrintRow(1, "Price")  [1b 51 41 50 72 69 63 65 0d]
+//	 000009  Brightness(3)  [1b 2a 03]
+//
+// The files are read as the hex dumps (plain hex, hexdump -C or xxd)
+// or as the binary data. The unknown sequences are marked with "!",
+// the exit status is 1 if there are unknown or incomplete commands.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// decoders are the decoders of the protocols
+var decoders = map[string]driver.Decoder{
+	"firich": firich.Decoder{},
+}
+
+func protocols() string {
+	var names []string
+	for name := range decoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	protocol := flag.String("protocol", "firich", "protocol of the display: "+protocols())
+	format := flag.String("format", "auto", "format of the input: auto, hex or bin")
+	encName := flag.String("encoding", "", "encoding of the text, e.g. cp866 or windows-1251")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: golddecode [flags] [file ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	decoder, ok := decoders[*protocol]
+	if !ok {
+		fatal(fmt.Errorf("unknown protocol %q, known: %s", *protocol, protocols()))
+	}
+	var enc encoding.Encoding
+	if *encName != "" {
+		var err error
+		if enc, err = ianaindex.IANA.Encoding(*encName); err != nil || enc == nil {
+			fatal(fmt.Errorf("unknown encoding %q", *encName))
+		}
+	}
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	clean := true
+	for _, name := range inputs {
+		data, err := read(name, *format)
+		if err != nil {
+			fatal(err)
+		}
+		if len(inputs) > 1 {
+			fmt.Fprintf(out, "# %s\n", name)
+		}
+		if !decode(out, decoder, enc, data) {
+			clean = false
+		}
+	}
+	out.Flush()
+	if !clean {
+		os.Exit(1)
+	}
+}
+
+func read(name, format string) ([]byte, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "bin":
+		return data, nil
+	case "hex":
+		return parseHex(string(data))
+	case "auto":
+		if b, err := parseHex(string(data)); err == nil {
+			return b, nil
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// decode writes the commands of the data and reports
+// whether all the commands are known
+func decode(w io.Writer, decoder driver.Decoder, enc encoding.Encoding, data []byte) bool {
+	cmds, rest := decoder.Decode(data)
+	clean := true
+	offset := 0
+	for _, cmd := range cmds {
+		mark := " "
+		if cmd.Name == driver.Unknown {
+			mark = "!"
+			clean = false
+		}
+		fmt.Fprintf(w, "%s%06x  %v  [% x]\n", mark, offset, decodeText(cmd, enc), cmd.Raw)
+		offset += len(cmd.Raw)
+	}
+	if len(rest) > 0 {
+		fmt.Fprintf(w, "!%06x  incomplete  [% x]\n", offset, rest)
+		clean = false
+	}
+	return clean
+}
+
+// decodeText decodes the text arguments from the encoding
+func decodeText(cmd driver.Command, enc encoding.Encoding) driver.Command {
+	if enc == nil {
+		return cmd
+	}
+	args := make([]interface{}, len(cmd.Args))
+	for i, arg := range cmd.Args {
+		if text, ok := arg.(string); ok {
+			if decoded, err := enc.NewDecoder().String(text); err == nil {
+				arg = decoded
+			}
+		}
+		args[i] = arg
+	}
+	cmd.Args = args
+	return cmd
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "golddecode:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arteev/gold/firich"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseHex(t *testing.T) {
+	want := []byte{0x1b, 0x51, 0x41, 0x48, 0x69, 0x0d}
+	for _, dump := range []string{
+		"1b 51 41 48 69 0d",
+		"1b5141\n48690d\n",
+		"0x1b, 0x51, 0x41, 0x48, 0x69, 0x0d",
+		"00000000  1b 51 41 48 69 0d                                 |.QAHi.|\n00000006\n",
+		"00000000: 1b51 4148 690d                           .QAHi.\n",
+	} {
+		got, err := parseHex(dump)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Excepted % x from %q, got % x, %v", want, dump, got, err)
+		}
+	}
+	if _, err := parseHex("Hello"); err == nil {
+		t.Error("Excepted error of the text")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var out bytes.Buffer
+	data := []byte("\x1b\x51\x42\x91\xe3\xac\xac\xa0\r\x1b\x99\x1b\x6c")
+	if decode(&out, firich.Decoder{}, charmap.CodePage866, data) {
+		t.Error("Excepted unknown commands are reported")
+	}
+	want := ` 000000  PrintRow(2, "Сумма")  [1b 51 42 91 e3 ac ac a0 0d]
+!000009  Unknown(1b 99)  [1b 99]
+!00000b  incomplete  [1b 6c]
+`
+	if out.String() != want {
+		t.Errorf("Excepted\n%s\ngot\n%s", want, out.String())
+	}
+}
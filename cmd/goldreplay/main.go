@@ -0,0 +1,117 @@
+// Command goldreplay replays the record of the session made by record.Recorder
+// against the device or the emulator:
+//
+//	goldreplay -port /dev/ttyUSB0 -baud 9600 session.rec
+//	goldreplay -speed 10 -encoding cp866 session.rec
+//
+// Without -port the record is replayed to the emulator and its screen
+// is printed at the end. The speed divides the pauses of the record,
+// zero replays without pauses.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/arteev/gold/emulator"
+	"github.com/arteev/gold/record"
+	"github.com/tarm/serial"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// emulators are the emulators of the protocols
+var emulators = map[string]func() *emulator.Emulator{
+	"firich": emulator.NewFirich,
+}
+
+func protocols() string {
+	var names []string
+	for name := range emulators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	port := flag.String("port", "", "serial port of the device, e.g. /dev/ttyUSB0 (default the emulator)")
+	baud := flag.Int("baud", 9600, "baud rate of the port")
+	protocol := flag.String("protocol", "firich", "protocol of the emulator: "+protocols())
+	encName := flag.String("encoding", "", "encoding of the text of the emulator, e.g. cp866")
+	speed := flag.Float64("speed", 1, "speed of the replay, 0 is without pauses")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: goldreplay [flags] file")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *speed < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	in := os.Stdin
+	if name := flag.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out io.WriteCloser
+	var dsp *emulator.Emulator
+	if *port != "" {
+		p, err := serial.OpenPort(&serial.Config{Name: *port, Baud: *baud})
+		if err != nil {
+			fatal(err)
+		}
+		out = p
+	} else {
+		newEmulator, ok := emulators[*protocol]
+		if !ok {
+			fatal(fmt.Errorf("unknown protocol %q, known: %s", *protocol, protocols()))
+		}
+		dsp = newEmulator()
+		if *encName != "" {
+			enc, err := ianaindex.IANA.Encoding(*encName)
+			if err != nil || enc == nil {
+				fatal(fmt.Errorf("unknown encoding %q", *encName))
+			}
+			dsp.SetEncoding(enc)
+		}
+		out = dsp
+	}
+	defer out.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	err := record.Replay(ctx, record.NewReader(in), out, *speed)
+	if dsp != nil {
+		screen := dsp.Screen()
+		for row := 1; row <= screen.Rows; row++ {
+			fmt.Println(dsp.Row(row))
+		}
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "goldreplay:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,115 @@
+// Command goldsim is the virtual pole display. It creates the pseudo terminal,
+// prints its path and shows the text written to it in the terminal:
+//
+//	goldsim -protocol firich -encoding cp866
+//	goldsim: firich display on /dev/pts/3
+//
+// Any program can write to the printed path as to /dev/ttyUSB0.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/arteev/gold/emulator"
+	"github.com/creack/pty"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// emulators are the emulators of the protocols
+var emulators = map[string]func() *emulator.Emulator{
+	"firich": emulator.NewFirich,
+}
+
+func protocols() string {
+	var names []string
+	for name := range emulators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	protocol := flag.String("protocol", "firich", "protocol of the display: "+protocols())
+	encName := flag.String("encoding", "", "encoding of the text, e.g. cp866 or windows-1251 (default ASCII)")
+	flag.Parse()
+
+	newEmulator, ok := emulators[*protocol]
+	if !ok {
+		fatal(fmt.Errorf("unknown protocol %q, known: %s", *protocol, protocols()))
+	}
+	var enc encoding.Encoding
+	if *encName != "" {
+		var err error
+		if enc, err = ianaindex.IANA.Encoding(*encName); err != nil || enc == nil {
+			fatal(fmt.Errorf("unknown encoding %q", *encName))
+		}
+	}
+
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		fatal(err)
+	}
+	defer ptmx.Close()
+	defer tty.Close()
+	// the port of the display is raw, the bytes are passed as is
+	if err := makeRaw(int(tty.Fd())); err != nil {
+		fatal(err)
+	}
+
+	emu := newEmulator()
+	changed := make(chan struct{}, 1)
+	failed := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := ptmx.Read(buf)
+			if n > 0 {
+				emu.Write(buf[:n])
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+			if err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	title := fmt.Sprintf("goldsim: %s display on %s", *protocol, tty.Name())
+	fmt.Print(hideCursor + clearScreen)
+	defer fmt.Print(showCursor)
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	r := &renderer{title: title, encoding: enc}
+	for {
+		os.Stdout.WriteString(r.render(emu.Screen(), time.Now()))
+		select {
+		case <-signals:
+			return
+		case err := <-failed:
+			fatal(err)
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprint(os.Stderr, showCursor)
+	fmt.Fprintln(os.Stderr, "goldsim:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arteev/gold/emulator"
+	"golang.org/x/text/encoding"
+)
+
+// ANSI sequences
+const (
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	clearScreen = "\x1b[2J"
+	home        = "\x1b[H"
+	reset       = "\x1b[0m"
+	reverse     = "\x1b[7m"
+)
+
+// glow are the colors of the brightness levels of the vacuum fluorescent display
+var glow = []string{
+	"\x1b[2;90m",
+	"\x1b[2;36m",
+	"\x1b[36m",
+	"\x1b[96m",
+	"\x1b[1;96m",
+}
+
+// renderer draws the screen of the display in the box
+type renderer struct {
+	title    string
+	encoding encoding.Encoding
+}
+
+func (r *renderer) render(scr emulator.Screen, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(home)
+	b.WriteString(r.title + "\n")
+
+	level := int(scr.Brightness)
+	if level >= len(glow) {
+		level = len(glow) - 1
+	}
+	color := glow[level]
+	// the blinking display is dark in the second half of the interval
+	dark := scr.Blink > 0 && now.UnixNano()/int64(scr.Blink)%2 == 1
+
+	border := strings.Repeat("─", scr.Cols)
+	b.WriteString("┌" + border + "┐\n")
+	for i, row := range r.rows(scr) {
+		b.WriteString("│" + color)
+		for j, c := range row {
+			if dark {
+				c = ' '
+			}
+			cursor := scr.CursorVisible && scr.CursorRow == i+1 && scr.CursorCol == j+1
+			if cursor {
+				b.WriteString(reverse)
+			}
+			b.WriteRune(c)
+			if cursor {
+				b.WriteString(reset + color)
+			}
+		}
+		b.WriteString(reset + "│\n")
+	}
+	b.WriteString("└" + border + "┘\n")
+	b.WriteString(status(scr) + "\x1b[K\n")
+	return b.String()
+}
+
+// rows returns the characters of the rows. The clock replaces the text.
+func (r *renderer) rows(scr emulator.Screen) [][]rune {
+	rows := make([][]rune, scr.Rows)
+	for i, cells := range scr.Cells {
+		rows[i] = make([]rune, len(cells))
+		for j, c := range cells {
+			rows[i][j] = r.char(scr, c)
+		}
+	}
+	if scr.ClockShown && scr.Rows > 0 {
+		clock := fmt.Sprintf("%02d:%02d", scr.Hour, scr.Minute)
+		line := []rune(fmt.Sprintf("%*s", (scr.Cols+len(clock))/2, clock))
+		rows[0] = []rune(strings.Repeat(" ", scr.Cols))
+		copy(rows[0], line)
+	}
+	return rows
+}
+
+func (r *renderer) char(scr emulator.Screen, c byte) rune {
+	if _, ok := scr.Glyphs[c]; ok && scr.UserChars {
+		return '▒'
+	}
+	if c < 0x80 {
+		return rune(c)
+	}
+	if r.encoding != nil {
+		if s, err := r.encoding.NewDecoder().Bytes([]byte{c}); err == nil {
+			return []rune(string(s))[0]
+		}
+	}
+	return '?'
+}
+
+// status shows the annunciator flags, the brightness and the cursor
+func status(scr emulator.Screen) string {
+	last := 8
+	for flag := range scr.Flags {
+		if int(flag) > last {
+			last = int(flag)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("flags ")
+	for flag := 1; flag <= last; flag++ {
+		if scr.Flags[byte(flag)] {
+			b.WriteString("■")
+		} else {
+			b.WriteString("□")
+		}
+	}
+	modes := map[emulator.Mode]string{
+		emulator.Rewrite: "rewrite",
+		emulator.VScroll: "vscroll",
+		emulator.HScroll: "hscroll",
+	}
+	fmt.Fprintf(&b, "  brightness %d  cursor %d,%d  %s", scr.Brightness, scr.CursorRow, scr.CursorCol, modes[scr.Mode])
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/emulator"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestRender(t *testing.T) {
+	emu := emulator.NewFirich()
+	emu.Write([]byte("\x1b\x40\x1b\x51\x42\x91\xe3\xac\xac\xa0\r\x1f\x23\x01\x02\x1b\x2a\x01"))
+	r := &renderer{title: "test", encoding: charmap.CodePage866}
+	got := r.render(emu.Screen(), time.Now())
+	for _, want := range []string{
+		"│" + glow[1] + "Сумма               " + reset + "│\n",
+		"flags □■□□□□□□  brightness 1  cursor 1,1  rewrite",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Excepted %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestStatusLastFlag(t *testing.T) {
+	scr := emulator.Screen{Flags: map[byte]bool{255: true}}
+	got := status(scr)
+	if want := "flags " + strings.Repeat("□", 254) + "■ "; !strings.HasPrefix(got, want) {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+}
@@ -1,6 +1,7 @@
 package display
 
 import (
+	"errors"
 	"sort"
 	"sync"
 
@@ -28,6 +29,9 @@ import (
 
 
 */
+// ErrUnknownDriver is returned by Open for the name of the unregistered driver
+var ErrUnknownDriver = errors.New("dsp: unknown driver")
+
 var (
 	muDrivers sync.RWMutex
 	drivers   = make(map[string]driver.Driver)
@@ -43,7 +47,7 @@ func Open(driverName string, configDriver map[string]interface{}) (*DSP, error)
 	drv, ok := drivers[driverName]
 	muDrivers.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("dsp: unknown driver %q", driverName)
+		return nil, fmt.Errorf("%w %q", ErrUnknownDriver, driverName)
 	}
 	dsp := &DSP{
 		driver: drv,
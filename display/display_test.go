@@ -99,7 +99,7 @@ func TestOpen(t *testing.T) {
 	}
 
 	fake := "fake"
-	if _, err := Open(fake, nil); err == nil || err.Error() != fmt.Sprintf("dsp: unknown driver %q", fake) {
+	if _, err := Open(fake, nil); err == nil || err.Error() != fmt.Sprintf("dsp: unknown driver %q", fake) || !errors.Is(err, ErrUnknownDriver) {
 		t.Errorf("Excepted: dsp: unknown driver %q", fake)
 	}
 }
@@ -1,13 +1,54 @@
 package driver
 
+import "context"
 import "errors"
+import "fmt"
+import "strconv"
+import "strings"
+import "time"
+import "unicode/utf8"
 import "golang.org/x/text/encoding"
 
 //Errors
 var (
 	ErrNotSupported = errors.New("Command is not supported")
+	ErrNotOpen      = errors.New("The device is not initialized")
+	ErrShortWrite   = errors.New("Short write")
+	ErrTimeout      = errors.New("Timeout")
+	ErrInvalidRow   = errors.New("Invalid row")
+	ErrEncoding     = errors.New("Text is not encodable")
 )
 
+// ShortWriteError is returned when the device accepted less bytes
+// than written. It matches ErrShortWrite.
+type ShortWriteError struct {
+	Want    int
+	Written int
+}
+
+func (e *ShortWriteError) Error() string {
+	return fmt.Sprintf("Error write: must %d byte(s), but %d byte(s)", e.Want, e.Written)
+}
+
+func (e *ShortWriteError) Unwrap() error {
+	return ErrShortWrite
+}
+
+// ProtocolError is the error of the command of the protocol,
+// e.g. the command is not supported by the device
+type ProtocolError struct {
+	Command string
+	Err     error
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Command + ": " + e.Err.Error()
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
 type Driver interface {
 	GetDisplay(proto Protocol, config map[string]interface{}) (Display, error)
 }
@@ -50,10 +91,89 @@ type Display interface {
 
 	//Text
 	PrintRow(row byte, text string) error
+	Print(text string) error
+	CharSize(width, height byte) error
 
 	//Flags
 	FlagEnable(enabled bool, num byte) error
 	FlagsDisable() error
+
+	//Clock
+	SetTime(t time.Time) error
+	ShowClock() error
+
+	//Attributes
+	Blink(interval time.Duration) error
+	BlinkRow(row byte, interval time.Duration) error
+	Reverse(bool) error
+	Underline(bool) error
+
+	//Character set
+	SetCodeTable(table byte) error
+	SetCharset(charset byte) error
+
+	//User-defined characters
+	DefineChar(code byte, glyph []byte) error
+	UserChars(enabled bool) error
+
+	//Status
+	Status() (Status, error)
+}
+
+// ContextDisplay is the Display whose commands honor the deadline
+// and the cancellation of the context
+type ContextDisplay interface {
+	Display
+
+	InitContext(ctx context.Context) error
+	TestContext(ctx context.Context) error
+	ClearContext(ctx context.Context) error
+
+	SendContext(ctx context.Context, data []byte) error
+	ReceiveContext(ctx context.Context, b []byte) (n int, err error)
+
+	ModeRewriteContext(ctx context.Context) error
+	ModeVScrollContext(ctx context.Context) error
+	ModeHScrollContext(ctx context.Context) error
+
+	BrightnessContext(ctx context.Context, value byte) error
+
+	ClearRowContext(ctx context.Context) error
+
+	CursorVisibleContext(ctx context.Context, visible bool) error
+	CursorMoveUpContext(ctx context.Context) error
+	CursorMoveDownContext(ctx context.Context) error
+	CursorMoveRightContext(ctx context.Context) error
+	CursorMoveLeftContext(ctx context.Context) error
+
+	CursorMoveLeftTopContext(ctx context.Context) error
+	CursorMoveBeginInRowContext(ctx context.Context) error
+	CursorMoveEndInRowContext(ctx context.Context) error
+	CursorMoveBottomContext(ctx context.Context) error
+	CursorMoveContext(ctx context.Context, row, col byte) error
+
+	PrintRowContext(ctx context.Context, row byte, text string) error
+	PrintContext(ctx context.Context, text string) error
+	CharSizeContext(ctx context.Context, width, height byte) error
+
+	FlagEnableContext(ctx context.Context, enabled bool, num byte) error
+	FlagsDisableContext(ctx context.Context) error
+
+	SetTimeContext(ctx context.Context, t time.Time) error
+	ShowClockContext(ctx context.Context) error
+
+	BlinkContext(ctx context.Context, interval time.Duration) error
+	BlinkRowContext(ctx context.Context, row byte, interval time.Duration) error
+	ReverseContext(ctx context.Context, enabled bool) error
+	UnderlineContext(ctx context.Context, enabled bool) error
+
+	SetCodeTableContext(ctx context.Context, table byte) error
+	SetCharsetContext(ctx context.Context, charset byte) error
+
+	DefineCharContext(ctx context.Context, code byte, glyph []byte) error
+	UserCharsContext(ctx context.Context, enabled bool) error
+
+	StatusContext(ctx context.Context) (Status, error)
 }
 
 // Protocol specific to a particular communication protocol
@@ -87,8 +207,200 @@ type Protocol interface {
 
 	//Text
 	PrintRowCmd(row byte, text string) []byte
+	PrintCmd(text string) []byte
+	CharSizeCmd(width, height byte) []byte
 
 	//Flags
 	FlagEnableCmd(enabled bool, num byte) []byte
 	FlagsDisableCmd() []byte
+
+	//Clock
+	SetTimeCmd(hour, minute byte) []byte
+	ShowClockCmd() []byte
+
+	//Attributes
+	BlinkCmd(interval time.Duration) []byte
+	ReverseCmd(bool) []byte
+	UnderlineCmd(bool) []byte
+
+	//Character set
+	CodeTableCmd(table byte) []byte
+	CharsetCmd(charset byte) []byte
+
+	//User-defined characters
+	DefineCharCmd(code byte, glyph []byte) []byte
+	UserCharsCmd(enabled bool) []byte
+}
+
+// CodeTabler is implemented by the protocols which know the numbers
+// of the device code tables matching the encodings
+type CodeTabler interface {
+	CodeTable(encoding.Encoding) (table byte, ok bool)
+}
+
+// TextMeasurer is implemented by the displays which know the number
+// of the cells the text takes on the display after the encoding
+type TextMeasurer interface {
+	TextWidth(text string) int
+}
+
+// TextWidth returns the number of the cells of the text on the display,
+// the number of the runes if the display is not a TextMeasurer
+func TextWidth(dsp Display, text string) int {
+	if m, ok := dsp.(TextMeasurer); ok {
+		return m.TextWidth(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// Sizer is implemented by the protocols of the displays of the fixed size
+type Sizer interface {
+	Size() (rows, cols byte)
+}
+
+// Timing is the pacing of the commands needed by the device
+type Timing struct {
+	// AfterInit, AfterClear and AfterBrightness are the delays
+	// before the command following Init, Clear or Brightness
+	AfterInit       time.Duration
+	AfterClear      time.Duration
+	AfterBrightness time.Duration
+	// BytesPerSecond limits the rate of the writes. Zero means no limit.
+	BytesPerSecond int
+}
+
+// Paced is implemented by the protocols of the devices which drop
+// the bytes written too fast
+type Paced interface {
+	Timing() Timing
+}
+
+// Status is the state reported by the device
+type Status struct {
+	// Online is false if the device does not answer
+	Online bool
+	// Error is the error reported by the device, e.g. the failure of the display
+	Error    bool
+	Model    string
+	Firmware string
+	// Raw is the answer to the status request
+	Raw []byte
+}
+
+// StatusQuerier is implemented by the protocols of the devices
+// answering the status and the identification requests
+type StatusQuerier interface {
+	// StatusQuery returns the status request and the length of the answer
+	StatusQuery() (cmd []byte, size int)
+	ParseStatus(answer []byte) (Status, error)
+	// IdentifyQuery returns the request of the model and the firmware
+	// and the maximal length of the answer. Nil request means no identification.
+	IdentifyQuery() (cmd []byte, size int)
+	ParseIdentify(answer []byte, status *Status) error
+}
+
+// Direction is the direction of the data of the event
+type Direction int
+
+// Directions
+const (
+	Write Direction = iota
+	Read
+)
+
+func (d Direction) String() string {
+	if d == Read {
+		return "read"
+	}
+	return "write"
+}
+
+// Event is the command sent to the display or the data received from it
+type Event struct {
+	Time    time.Time
+	Command string
+	// Args are the arguments of the command as passed to the display
+	Args []interface{}
+	// Data are the bytes written or read. The observer must not modify them.
+	Data      []byte
+	Direction Direction
+	Duration  time.Duration
+	Err       error
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s(%s) %s % x in %v", e.Command, formatArgs(e.Args), e.Direction, e.Data, e.Duration)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Reconnect is the command of the event reported when the port
+// of the display is created again, e.g. after reopening the device
+const Reconnect = "Reconnect"
+
+// Observer receives the events of the display. It is called synchronously
+// by the goroutine of the command and must not call the display.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc is the function used as the Observer
+type ObserverFunc func(e Event)
+
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// Observable is implemented by the displays reporting the events
+type Observable interface {
+	AddObserver(o Observer)
+}
+
+// Command is the command decoded from the bytes written to the device.
+// The name and the arguments are the ones of the Display method writing
+// the command if there is one.
+type Command struct {
+	Name string
+	Args []interface{}
+	// Raw are the bytes of the command
+	Raw []byte
+}
+
+// Unknown is the name of the command of the unknown sequence of bytes
+const Unknown = "Unknown"
+
+func (c Command) String() string {
+	if c.Name == Unknown {
+		return fmt.Sprintf("%s(% x)", c.Name, c.Raw)
+	}
+	return c.Name + "(" + formatArgs(c.Args) + ")"
+}
+
+func formatArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			s[i] = strconv.Quote(arg)
+		case []byte:
+			s[i] = fmt.Sprintf("% x", arg)
+		default:
+			s[i] = fmt.Sprint(arg)
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+// Decoder turns the bytes written to the device back into the commands
+type Decoder interface {
+	// Decode returns the commands of the data and the rest of the data
+	// which is the beginning of an incomplete command
+	Decode(data []byte) (cmds []Command, rest []byte)
+}
+
+// Decodable is implemented by the protocols which have the decoder of their commands
+type Decodable interface {
+	Decoder() Decoder
 }
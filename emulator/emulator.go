@@ -0,0 +1,371 @@
+package emulator
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"golang.org/x/text/encoding"
+)
+
+// Mode is the mode of writing the text past the end of the row
+type Mode int
+
+// Modes
+const (
+	// Rewrite continues at the beginning of the next row or the top row
+	Rewrite Mode = iota
+	// VScroll scrolls the rows up at the end of the bottom row
+	VScroll
+	// HScroll scrolls the row left at the end of the row
+	HScroll
+)
+
+// DefaultBrightness is the brightness after Init
+const DefaultBrightness = 4
+
+// Screen is the state of the emulated display. The rows and the columns are numbered from 1.
+type Screen struct {
+	Rows, Cols int
+	// Cells are the codes of the characters of the rows
+	Cells [][]byte
+
+	CursorRow, CursorCol int
+	CursorVisible        bool
+	Mode                 Mode
+	Brightness           byte
+	Flags                map[byte]bool
+	Blink                time.Duration
+
+	Hour, Minute byte
+	ClockShown   bool
+
+	CodeTable byte
+	Charset   byte
+	UserChars bool
+	// Glyphs are the user-defined characters
+	Glyphs map[byte][]byte
+}
+
+func newScreen(rows, cols int) Screen {
+	s := Screen{Rows: rows, Cols: cols}
+	s.reset()
+	return s
+}
+
+func (s *Screen) reset() {
+	*s = Screen{
+		Rows:       s.Rows,
+		Cols:       s.Cols,
+		CursorRow:  1,
+		CursorCol:  1,
+		Brightness: DefaultBrightness,
+		Flags:      make(map[byte]bool),
+		Glyphs:     make(map[byte][]byte),
+	}
+	s.clear()
+}
+
+func (s *Screen) clear() {
+	s.Cells = make([][]byte, s.Rows)
+	for i := range s.Cells {
+		s.Cells[i] = []byte(strings.Repeat(" ", s.Cols))
+	}
+	s.CursorRow, s.CursorCol = 1, 1
+	s.ClockShown = false
+}
+
+func (s Screen) copy() Screen {
+	c := s
+	c.Cells = make([][]byte, len(s.Cells))
+	for i, row := range s.Cells {
+		c.Cells[i] = append([]byte(nil), row...)
+	}
+	c.Flags = make(map[byte]bool, len(s.Flags))
+	for k, v := range s.Flags {
+		c.Flags[k] = v
+	}
+	c.Glyphs = make(map[byte][]byte, len(s.Glyphs))
+	for k, v := range s.Glyphs {
+		c.Glyphs[k] = append([]byte(nil), v...)
+	}
+	return c
+}
+
+// put writes the character at the cursor. The cursor past the end of the row
+// is moved by the mode before the next character.
+func (s *Screen) put(c byte) {
+	if s.CursorCol > s.Cols {
+		switch s.Mode {
+		case Rewrite:
+			s.CursorCol = 1
+			s.CursorRow = s.CursorRow%s.Rows + 1
+		case VScroll:
+			s.CursorCol = 1
+			s.lineFeed()
+		case HScroll:
+			row := s.Cells[s.CursorRow-1]
+			copy(row, row[1:])
+			s.CursorCol = s.Cols
+		}
+	}
+	s.Cells[s.CursorRow-1][s.CursorCol-1] = c
+	s.CursorCol++
+	s.ClockShown = false
+}
+
+func (s *Screen) lineFeed() {
+	if s.CursorRow < s.Rows {
+		s.CursorRow++
+		return
+	}
+	if s.Mode != VScroll {
+		s.CursorRow = 1
+		return
+	}
+	copy(s.Cells, s.Cells[1:])
+	s.Cells[s.Rows-1] = []byte(strings.Repeat(" ", s.Cols))
+}
+
+func (s *Screen) moveTo(row, col int) {
+	if row < 1 {
+		row = 1
+	}
+	if row > s.Rows {
+		row = s.Rows
+	}
+	if col < 1 {
+		col = 1
+	}
+	if col > s.Cols {
+		col = s.Cols
+	}
+	s.CursorRow, s.CursorCol = row, col
+}
+
+// apply changes the screen by the command
+func (s *Screen) apply(cmd driver.Command) {
+	arg := func(i int) byte {
+		return cmd.Args[i].(byte)
+	}
+	switch cmd.Name {
+	case "Init":
+		s.reset()
+	case "Clear":
+		s.clear()
+	case "ClearRow":
+		s.Cells[s.CursorRow-1] = []byte(strings.Repeat(" ", s.Cols))
+		s.CursorCol = 1
+	case "CarriageReturn":
+		s.CursorCol = 1
+	case "LineFeed":
+		s.lineFeed()
+	case "Print":
+		text := cmd.Args[0].(string)
+		for i := 0; i < len(text); i++ {
+			s.put(text[i])
+		}
+	case "PrintRow":
+		row := int(arg(0))
+		if row < 1 || row > s.Rows {
+			return
+		}
+		line := []byte(strings.Repeat(" ", s.Cols))
+		copy(line, cmd.Args[1].(string))
+		s.Cells[row-1] = line
+		s.ClockShown = false
+	case "CursorVisible":
+		s.CursorVisible = cmd.Args[0].(bool)
+	case "ModeRewrite":
+		s.Mode = Rewrite
+	case "ModeVScroll":
+		s.Mode = VScroll
+	case "ModeHScroll":
+		s.Mode = HScroll
+	case "Brightness":
+		s.Brightness = arg(0)
+	case "CursorMoveUp":
+		s.moveTo(s.CursorRow-1, s.CursorCol)
+	case "CursorMoveDown":
+		s.moveTo(s.CursorRow+1, s.CursorCol)
+	case "CursorMoveRight":
+		if s.CursorCol >= s.Cols {
+			s.CursorCol = 1
+			s.CursorRow = s.CursorRow%s.Rows + 1
+			return
+		}
+		s.CursorCol++
+	case "CursorMoveLeft":
+		if s.CursorCol > 1 {
+			s.CursorCol--
+		} else if s.CursorRow > 1 {
+			s.moveTo(s.CursorRow-1, s.Cols)
+		}
+	case "CursorMoveLeftTop":
+		s.moveTo(1, 1)
+	case "CursorMoveBeginInRow":
+		s.CursorCol = 1
+	case "CursorMoveEndInRow":
+		s.CursorCol = s.Cols
+	case "CursorMoveBottom":
+		s.moveTo(s.Rows, 1)
+	case "CursorMove":
+		s.moveTo(int(arg(0)), int(arg(1)))
+	case "FlagEnable":
+		s.Flags[arg(1)] = cmd.Args[0].(bool)
+	case "FlagsDisable":
+		s.Flags = make(map[byte]bool)
+	case "SetTime":
+		s.Hour, s.Minute = arg(0), arg(1)
+	case "ShowClock":
+		s.ClockShown = true
+	case "Blink":
+		s.Blink = cmd.Args[0].(time.Duration)
+	case "SetCodeTable":
+		s.CodeTable = arg(0)
+	case "SetCharset":
+		s.Charset = arg(0)
+	case "DefineChar":
+		s.Glyphs[arg(0)] = cmd.Args[1].([]byte)
+	case "UserChars":
+		s.UserChars = cmd.Args[0].(bool)
+	}
+}
+
+// Emulator is the virtual display written through com.Serial. It decodes
+// the bytes by the decoder of the protocol and keeps the screen.
+type Emulator struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	decoder  driver.Decoder
+	encoding encoding.Encoding
+	screen   Screen
+	commands []driver.Command
+	rest     []byte
+	answer   []byte
+	closed   bool
+}
+
+// New returns the emulator of the display of the size
+func New(decoder driver.Decoder, rows, cols int) *Emulator {
+	e := &Emulator{
+		decoder: decoder,
+		screen:  newScreen(rows, cols),
+	}
+	e.cond = sync.NewCond(&e.mu)
+	return e
+}
+
+// NewFirich returns the emulator of the Firich display of 2 rows of 20 characters
+func NewFirich() *Emulator {
+	return New(firich.Decoder{}, 2, 20)
+}
+
+// SetEncoding sets the encoding of the text returned by Row. Nil means ASCII.
+func (e *Emulator) SetEncoding(enc encoding.Encoding) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoding = enc
+}
+
+// Write decodes the commands and applies them to the screen
+func (e *Emulator) Write(b []byte) (n int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return 0, io.ErrClosedPipe
+	}
+	data := append(e.rest, b...)
+	cmds, rest := e.decoder.Decode(data)
+	e.rest = append([]byte(nil), rest...)
+	for _, cmd := range cmds {
+		cmd.Raw = append([]byte(nil), cmd.Raw...)
+		e.screen.apply(cmd)
+		e.commands = append(e.commands, cmd)
+	}
+	return len(b), nil
+}
+
+// Apply applies the commands to the screen as if they were written
+// and decoded, e.g. the calls of the display recorded without a protocol
+func (e *Emulator) Apply(cmds ...driver.Command) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, cmd := range cmds {
+		e.screen.apply(cmd)
+		e.commands = append(e.commands, cmd)
+	}
+}
+
+// Read returns the answer of the display set by Respond.
+// It waits for the answer until the emulator is closed.
+func (e *Emulator) Read(b []byte) (n int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for len(e.answer) == 0 && !e.closed {
+		e.cond.Wait()
+	}
+	if len(e.answer) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(b, e.answer)
+	e.answer = e.answer[n:]
+	return n, nil
+}
+
+// Close stops the reads
+func (e *Emulator) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	e.cond.Broadcast()
+	return nil
+}
+
+// Respond adds the bytes read from the display
+func (e *Emulator) Respond(b []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.answer = append(e.answer, b...)
+	e.cond.Broadcast()
+}
+
+// Screen returns the copy of the screen
+func (e *Emulator) Screen() Screen {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.screen.copy()
+}
+
+// Row returns the text of the row without the trailing spaces
+func (e *Emulator) Row(row int) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if row < 1 || row > e.screen.Rows {
+		return ""
+	}
+	text := strings.TrimRight(string(e.screen.Cells[row-1]), " ")
+	if e.encoding != nil {
+		if decoded, err := e.encoding.NewDecoder().String(text); err == nil {
+			return decoded
+		}
+	}
+	return text
+}
+
+// Commands returns the commands written to the display
+func (e *Emulator) Commands() []driver.Command {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]driver.Command(nil), e.commands...)
+}
+
+// Reset clears the log of the commands
+func (e *Emulator) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commands = nil
+}
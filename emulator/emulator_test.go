@@ -0,0 +1,93 @@
+package emulator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"github.com/arteev/gold/serial/com"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func open(t *testing.T) (*com.Serial, *Emulator) {
+	emu := NewFirich()
+	s := com.MustSerial(firich.FirichProtocol{})
+	s.SetTiming(driver.Timing{})
+	s.CreatePort(emu)
+	return s, emu
+}
+
+func TestFirichScreen(t *testing.T) {
+	s, emu := open(t)
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetSelectCodeTable(true)
+	s.SetEncoding(charmap.CodePage866)
+	emu.SetEncoding(charmap.CodePage866)
+	s.PrintRow(1, "Молоко")
+	s.PrintRow(2, "Total:20$")
+	s.Brightness(2)
+	s.CursorVisible(true)
+	s.FlagEnable(true, 3)
+	s.ModeVScroll()
+	s.CursorMove(2, 5)
+
+	if got := emu.Row(1); got != "Молоко" {
+		t.Errorf("Excepted row 1 %q, got %q", "Молоко", got)
+	}
+	if got := emu.Row(2); got != "Total:20$" {
+		t.Errorf("Excepted row 2 %q, got %q", "Total:20$", got)
+	}
+	scr := emu.Screen()
+	if scr.Brightness != 2 || !scr.CursorVisible || !scr.Flags[3] || scr.Mode != VScroll ||
+		scr.CursorRow != 2 || scr.CursorCol != 5 || scr.CodeTable != 17 {
+		t.Errorf("Excepted state of the screen, got %+v", scr)
+	}
+
+	// the text past the bottom row scrolls the rows up
+	s.Print("12345678901234567890X")
+	if emu.Row(1) != "Tota1234567890123456" || emu.Row(2) != "7890X" {
+		t.Errorf("Excepted scrolled rows, got %q and %q", emu.Row(1), emu.Row(2))
+	}
+
+	s.Clear()
+	if scr := emu.Screen(); emu.Row(1) != "" || scr.CursorRow != 1 || scr.CursorCol != 1 {
+		t.Errorf("Excepted clear screen, got %q %+v", emu.Row(1), scr)
+	}
+}
+
+func TestDecodeSplit(t *testing.T) {
+	emu := NewFirich()
+	emu.Write([]byte("\x1b\x51\x41Pri"))
+	if len(emu.Commands()) != 0 {
+		t.Fatalf("Excepted incomplete command, got %v", emu.Commands())
+	}
+	emu.Write([]byte("ce\r\x1f\x45\x0a\x1b\x99"))
+	want := []string{`PrintRow(1, "Price")`, `Blink(500ms)`, `Unknown(1b 99)`}
+	var got []string
+	for _, cmd := range emu.Commands() {
+		got = append(got, cmd.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+	if got := emu.Screen().Blink; got != 500*time.Millisecond {
+		t.Errorf("Excepted blink 500ms, got %v", got)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	s, emu := open(t)
+	emu.Respond([]byte{0x06})
+	got, err := s.Query([]byte{0x05}, 1, time.Second)
+	if err != nil || !reflect.DeepEqual(got, []byte{0x06}) {
+		t.Errorf("Excepted answer [6], got %v, %v", got, err)
+	}
+	emu.Close()
+	if _, err := s.Receive(make([]byte, 1)); err == nil {
+		t.Error("Excepted error of the closed emulator")
+	}
+}
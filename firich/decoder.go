@@ -0,0 +1,208 @@
+package firich
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+const (
+	dle = 0x10
+	esc = 0x1b
+	gs  = 0x1d
+	us  = 0x1f
+)
+
+// Decoder decodes the bytes written by FirichProtocol back into the commands
+// named as the methods of driver.Display, e.g. PrintRow(1, "Price:10$").
+// The unknown sequences are decoded as driver.Unknown commands.
+type Decoder struct{}
+
+// escCommands are the commands ESC x without the arguments
+var escCommands = map[byte]string{
+	0x40: "Init",
+	0x11: "ModeRewrite",
+	0x12: "ModeVScroll",
+	0x13: "ModeHScroll",
+	0x7a: "FlagsDisable",
+}
+
+// cursorCommands are the commands ESC [ x
+var cursorCommands = map[byte]string{
+	0x41: "CursorMoveUp",
+	0x42: "CursorMoveDown",
+	0x43: "CursorMoveRight",
+	0x44: "CursorMoveLeft",
+	0x48: "CursorMoveLeftTop",
+	0x4c: "CursorMoveBeginInRow",
+	0x52: "CursorMoveEndInRow",
+	0x4b: "CursorMoveBottom",
+}
+
+// Decode decodes the data. The rest is the beginning of an incomplete command.
+func (d Decoder) Decode(data []byte) (cmds []driver.Command, rest []byte) {
+	for len(data) > 0 {
+		cmd, n := d.next(data)
+		if n == 0 {
+			return cmds, data
+		}
+		cmd.Raw = data[:n]
+		cmds = append(cmds, cmd)
+		data = data[n:]
+	}
+	return cmds, nil
+}
+
+// query decodes the request of 3 bytes: the prefix, the code and the argument
+func (d Decoder) query(data []byte, code byte, name string) (driver.Command, int) {
+	if len(data) < 2 {
+		return driver.Command{}, 0
+	}
+	if data[1] != code {
+		return unknown(), 1
+	}
+	if len(data) < 3 {
+		return driver.Command{}, 0
+	}
+	return driver.Command{Name: name, Args: []interface{}{data[2]}}, 3
+}
+
+// next decodes the first command of the data and returns its length.
+// Zero length means the command is incomplete.
+func (d Decoder) next(data []byte) (driver.Command, int) {
+	switch c := data[0]; {
+	case c == 0x0c:
+		return driver.Command{Name: "Clear"}, 1
+	case c == 0x18:
+		return driver.Command{Name: "ClearRow"}, 1
+	case c == 0x0d:
+		return driver.Command{Name: "CarriageReturn"}, 1
+	case c == 0x0a:
+		return driver.Command{Name: "LineFeed"}, 1
+	case c == esc:
+		return d.escape(data)
+	case c == us:
+		return d.unit(data)
+	case c == dle:
+		return d.query(data, 0x04, "Status")
+	case c == gs:
+		return d.query(data, 0x49, "Identify")
+	case c < 0x20:
+		return unknown(), 1
+	}
+	n := 0
+	for n < len(data) && data[n] >= 0x20 {
+		n++
+	}
+	return driver.Command{Name: "Print", Args: []interface{}{string(data[:n])}}, n
+}
+
+func unknown() driver.Command {
+	return driver.Command{Name: driver.Unknown}
+}
+
+func (d Decoder) escape(data []byte) (driver.Command, int) {
+	if len(data) < 2 {
+		return driver.Command{}, 0
+	}
+	if name, ok := escCommands[data[1]]; ok {
+		return driver.Command{Name: name}, 2
+	}
+	switch data[1] {
+	case 0x5f, 0x2a, 0x74, 0x52, 0x25:
+		if len(data) < 3 {
+			return driver.Command{}, 0
+		}
+		arg := data[2]
+		switch data[1] {
+		case 0x5f:
+			return driver.Command{Name: "CursorVisible", Args: []interface{}{arg != 0}}, 3
+		case 0x2a:
+			return driver.Command{Name: "Brightness", Args: []interface{}{arg}}, 3
+		case 0x74:
+			return driver.Command{Name: "SetCodeTable", Args: []interface{}{arg}}, 3
+		case 0x52:
+			return driver.Command{Name: "SetCharset", Args: []interface{}{arg}}, 3
+		}
+		return driver.Command{Name: "UserChars", Args: []interface{}{arg != 0}}, 3
+	case 0x5b:
+		if len(data) < 3 {
+			return driver.Command{}, 0
+		}
+		if name, ok := cursorCommands[data[2]]; ok {
+			return driver.Command{Name: name}, 3
+		}
+		return unknown(), 3
+	case 0x6c:
+		if len(data) < 4 {
+			return driver.Command{}, 0
+		}
+		return driver.Command{Name: "CursorMove", Args: []interface{}{data[3], data[2]}}, 4
+	case 0x51:
+		if len(data) < 3 {
+			return driver.Command{}, 0
+		}
+		if data[2] != 0x41 && data[2] != 0x42 {
+			return unknown(), 3
+		}
+		end := bytes.IndexByte(data[3:], 0x0d)
+		if end < 0 {
+			return driver.Command{}, 0
+		}
+		row := data[2] - 0x40
+		return driver.Command{Name: "PrintRow", Args: []interface{}{row, string(data[3 : 3+end])}}, 3 + end + 1
+	case 0x26:
+		return d.defineChar(data)
+	}
+	return unknown(), 2
+}
+
+// defineChar decodes ESC & 1 c1 c2 [w d1...dw]... The definition
+// of several characters at once is not written by the protocol and is unknown.
+func (d Decoder) defineChar(data []byte) (driver.Command, int) {
+	if len(data) < 5 {
+		return driver.Command{}, 0
+	}
+	first, last := data[3], data[4]
+	if data[2] != 0x01 || last < first {
+		return unknown(), 5
+	}
+	n := 5
+	for code := int(first); code <= int(last); code++ {
+		if len(data) < n+1 || len(data) < n+1+int(data[n]) {
+			return driver.Command{}, 0
+		}
+		n += 1 + int(data[n])
+	}
+	if first != last {
+		return unknown(), n
+	}
+	return driver.Command{Name: "DefineChar", Args: []interface{}{first, append([]byte(nil), data[6:n]...)}}, n
+}
+
+func (d Decoder) unit(data []byte) (driver.Command, int) {
+	if len(data) < 2 {
+		return driver.Command{}, 0
+	}
+	switch data[1] {
+	case 0x40:
+		return driver.Command{Name: "Test"}, 2
+	case 0x55:
+		return driver.Command{Name: "ShowClock"}, 2
+	case 0x45:
+		if len(data) < 3 {
+			return driver.Command{}, 0
+		}
+		return driver.Command{Name: "Blink", Args: []interface{}{time.Duration(data[2]) * 50 * time.Millisecond}}, 3
+	case 0x23, 0x54:
+		if len(data) < 4 {
+			return driver.Command{}, 0
+		}
+		if data[1] == 0x23 {
+			return driver.Command{Name: "FlagEnable", Args: []interface{}{data[2] != 0, data[3]}}, 4
+		}
+		return driver.Command{Name: "SetTime", Args: []interface{}{data[2], data[3]}}, 4
+	}
+	return unknown(), 2
+}
@@ -0,0 +1,75 @@
+package firich
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+var _ driver.Decodable = FirichProtocol{}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	p := FirichProtocol{}
+	cases := []struct {
+		Data []byte
+		Want driver.Command
+	}{
+		{p.InitCmd(), driver.Command{Name: "Init"}},
+		{p.ClearCmd(), driver.Command{Name: "Clear"}},
+		{p.TestCmd(), driver.Command{Name: "Test"}},
+		{p.ClearRowCmd(), driver.Command{Name: "ClearRow"}},
+		{p.CursorVisibleCmd(true), driver.Command{Name: "CursorVisible", Args: []interface{}{true}}},
+		{p.ModeRewriteCmd(), driver.Command{Name: "ModeRewrite"}},
+		{p.ModeVScrollCmd(), driver.Command{Name: "ModeVScroll"}},
+		{p.ModeHScrollCmd(), driver.Command{Name: "ModeHScroll"}},
+		{p.BrightnessCmd(3), driver.Command{Name: "Brightness", Args: []interface{}{byte(3)}}},
+		{p.PrintRowCmd(1, "Price:10$"), driver.Command{Name: "PrintRow", Args: []interface{}{byte(1), "Price:10$"}}},
+		{p.PrintRowCmd(2, ""), driver.Command{Name: "PrintRow", Args: []interface{}{byte(2), ""}}},
+		{p.PrintCmd("Milk"), driver.Command{Name: "Print", Args: []interface{}{"Milk"}}},
+		{p.CursorMoveUpCmd(), driver.Command{Name: "CursorMoveUp"}},
+		{p.CursorMoveDownCmd(), driver.Command{Name: "CursorMoveDown"}},
+		{p.CursorMoveRightCmd(), driver.Command{Name: "CursorMoveRight"}},
+		{p.CursorMoveLeftCmd(), driver.Command{Name: "CursorMoveLeft"}},
+		{p.CursorMoveLeftTopCmd(), driver.Command{Name: "CursorMoveLeftTop"}},
+		{p.CursorMoveBeginInRowCmd(), driver.Command{Name: "CursorMoveBeginInRow"}},
+		{p.CursorMoveEndInRowCmd(), driver.Command{Name: "CursorMoveEndInRow"}},
+		{p.CursorMoveBottomCmd(), driver.Command{Name: "CursorMoveBottom"}},
+		{p.CursorMoveCmd(2, 5), driver.Command{Name: "CursorMove", Args: []interface{}{byte(2), byte(5)}}},
+		{p.FlagEnableCmd(true, 4), driver.Command{Name: "FlagEnable", Args: []interface{}{true, byte(4)}}},
+		{p.FlagsDisableCmd(), driver.Command{Name: "FlagsDisable"}},
+		{p.SetTimeCmd(12, 30), driver.Command{Name: "SetTime", Args: []interface{}{byte(12), byte(30)}}},
+		{p.ShowClockCmd(), driver.Command{Name: "ShowClock"}},
+		{p.BlinkCmd(time.Second), driver.Command{Name: "Blink", Args: []interface{}{time.Second}}},
+		{p.CodeTableCmd(17), driver.Command{Name: "SetCodeTable", Args: []interface{}{byte(17)}}},
+		{p.CharsetCmd(7), driver.Command{Name: "SetCharset", Args: []interface{}{byte(7)}}},
+		{p.DefineCharCmd(0xf0, []byte{1, 2, 3, 4, 5}), driver.Command{Name: "DefineChar", Args: []interface{}{byte(0xf0), []byte{1, 2, 3, 4, 5}}}},
+		{p.UserCharsCmd(true), driver.Command{Name: "UserChars", Args: []interface{}{true}}},
+		{[]byte{0x10, 0x04, 0x01}, driver.Command{Name: "Status", Args: []interface{}{byte(1)}}},
+		{[]byte{0x1d, 0x49, 0x43}, driver.Command{Name: "Identify", Args: []interface{}{byte(0x43)}}},
+	}
+	for _, c := range cases {
+		cmds, rest := Decoder{}.Decode(c.Data)
+		c.Want.Raw = c.Data
+		if len(cmds) != 1 || len(rest) != 0 || !reflect.DeepEqual(cmds[0], c.Want) {
+			t.Errorf("Excepted %v from % x, got %v (rest % x)", c.Want, c.Data, cmds, rest)
+		}
+	}
+}
+
+func TestDecodeUnknown(t *testing.T) {
+	data := []byte("\x1b\x99\x01A\x1f\x7f\x1b\x51")
+	cmds, rest := Decoder{}.Decode(data)
+	var got []string
+	for _, cmd := range cmds {
+		got = append(got, cmd.String())
+	}
+	want := []string{"Unknown(1b 99)", "Unknown(01)", `Print("A")`, "Unknown(1f 7f)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+	if string(rest) != "\x1b\x51" {
+		t.Errorf("Excepted incomplete rest, got % x", rest)
+	}
+}
@@ -2,11 +2,58 @@ package firich
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
 )
 
 type FirichProtocol struct {
 }
 
+// codeTables are the numbers of the code tables selected by ESC t n
+var codeTables = map[encoding.Encoding]byte{
+	charmap.CodePage437: 0,
+	charmap.CodePage850: 2,
+	charmap.CodePage860: 3,
+	charmap.CodePage863: 4,
+	charmap.CodePage865: 5,
+	charmap.CodePage862: 15,
+	charmap.Windows1252: 16,
+	charmap.CodePage866: 17,
+	charmap.CodePage852: 18,
+	charmap.CodePage858: 19,
+	charmap.ISO8859_15:  40,
+	charmap.Windows1250: 45,
+	charmap.Windows1251: 46,
+	charmap.Windows1253: 47,
+	charmap.Windows1254: 48,
+	charmap.Windows1255: 49,
+	charmap.Windows1257: 51,
+}
+
+// Size returns the size of the Firich displays: 2 rows of 20 characters
+func (p FirichProtocol) Size() (rows, cols byte) {
+	return 2, 20
+}
+
+// Decoder returns the decoder of the commands of the protocol
+func (p FirichProtocol) Decoder() driver.Decoder {
+	return Decoder{}
+}
+
+// Timing returns the pacing of the Firich displays. The display is busy
+// for a while resetting after ESC @.
+func (p FirichProtocol) Timing() driver.Timing {
+	return driver.Timing{
+		AfterInit:  100 * time.Millisecond,
+		AfterClear: 5 * time.Millisecond,
+	}
+}
+
 //return []byte{}
 
 func (p FirichProtocol) InitCmd() []byte {
@@ -67,6 +114,15 @@ func (p FirichProtocol) PrintRowCmd(row byte, text string) []byte {
 	return nil
 }
 
+// PrintCmd writes the text at the cursor position
+func (p FirichProtocol) PrintCmd(text string) []byte {
+	return []byte(text)
+}
+
+func (p FirichProtocol) CharSizeCmd(width, height byte) []byte {
+	return nil
+}
+
 func (p FirichProtocol) CursorMoveUpCmd() []byte {
 	return []byte{0x1b, 0x5b, 0x41}
 }
@@ -107,3 +163,93 @@ func (p FirichProtocol) FlagEnableCmd(enabled bool, num byte) []byte {
 func (p FirichProtocol) FlagsDisableCmd() []byte {
 	return []byte{0x1b, 0x7a}
 }
+
+func (p FirichProtocol) SetTimeCmd(hour, minute byte) []byte {
+	return []byte{0x1f, 0x54, hour, minute}
+}
+
+func (p FirichProtocol) ShowClockCmd() []byte {
+	return []byte{0x1f, 0x55}
+}
+
+// BlinkCmd blinks the whole display, the interval is set in 50 ms steps.
+// Zero interval stops blinking.
+func (p FirichProtocol) BlinkCmd(interval time.Duration) []byte {
+	n := interval / (50 * time.Millisecond)
+	switch {
+	case interval <= 0:
+		n = 0
+	case n < 1:
+		n = 1
+	case n > 0xff:
+		n = 0xff
+	}
+	return []byte{0x1f, 0x45, byte(n)}
+}
+
+func (p FirichProtocol) ReverseCmd(bool) []byte {
+	return nil
+}
+
+func (p FirichProtocol) UnderlineCmd(bool) []byte {
+	return nil
+}
+
+func (p FirichProtocol) CodeTableCmd(table byte) []byte {
+	return []byte{0x1b, 0x74, table}
+}
+
+func (p FirichProtocol) CharsetCmd(charset byte) []byte {
+	return []byte{0x1b, 0x52, charset}
+}
+
+// DefineCharCmd defines the 5x7 character, the glyph is 5 columns (bit 0 is the top row)
+func (p FirichProtocol) DefineCharCmd(code byte, glyph []byte) []byte {
+	if len(glyph) != 5 {
+		return nil
+	}
+	return append([]byte{0x1b, 0x26, 0x01, code, code, 0x05}, glyph...)
+}
+
+func (p FirichProtocol) UserCharsCmd(enabled bool) []byte {
+	var vbyte byte
+	if enabled {
+		vbyte = 1
+	}
+	return []byte{0x1b, 0x25, vbyte}
+}
+
+// CodeTable returns the number of the code table of the encoding
+func (p FirichProtocol) CodeTable(enc encoding.Encoding) (byte, bool) {
+	table, ok := codeTables[enc]
+	return table, ok
+}
+
+// StatusQuery returns DLE EOT 1, the display answers 1 byte of the status
+func (p FirichProtocol) StatusQuery() ([]byte, int) {
+	return []byte{0x10, 0x04, 0x01}, 1
+}
+
+// ParseStatus parses the status byte: the bits 1 and 4 are set,
+// the bits 0 and 7 are clear, the bit 3 is the error of the display
+func (p FirichProtocol) ParseStatus(answer []byte) (driver.Status, error) {
+	if len(answer) != 1 || answer[0]&0x93 != 0x12 {
+		return driver.Status{}, fmt.Errorf("firich: invalid status % x", answer)
+	}
+	return driver.Status{Error: answer[0]&0x08 != 0, Raw: answer}, nil
+}
+
+// IdentifyQuery returns GS I C, the display answers its model name
+// as "_", the name and NUL
+func (p FirichProtocol) IdentifyQuery() ([]byte, int) {
+	return []byte{0x1d, 0x49, 0x43}, 32
+}
+
+// ParseIdentify parses the model name
+func (p FirichProtocol) ParseIdentify(answer []byte, status *driver.Status) error {
+	if len(answer) < 2 || answer[0] != '_' {
+		return errors.New("firich: invalid model name")
+	}
+	status.Model = string(bytes.TrimRight(answer[1:], "\x00"))
+	return nil
+}
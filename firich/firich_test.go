@@ -0,0 +1,34 @@
+package firich
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/protocoltest"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the commands")
+
+func TestConformance(t *testing.T) {
+	protocoltest.Run(t, FirichProtocol{}, protocoltest.Caps{
+		Unsupported: []string{"CharSize", "Reverse", "Underline"},
+		Golden:      "testdata",
+		Decoder:     Decoder{},
+		Update:      *update,
+	})
+}
+
+func TestStatus(t *testing.T) {
+	p := FirichProtocol{}
+	if status, err := p.ParseStatus([]byte{0x1a}); err != nil || !status.Error {
+		t.Errorf("Excepted error of the display, got %+v, %v", status, err)
+	}
+	if _, err := p.ParseStatus([]byte{0xff}); err == nil {
+		t.Error("Excepted invalid status")
+	}
+	var status driver.Status
+	if err := p.ParseIdentify([]byte("_FV-2030\x00"), &status); err != nil || status.Model != "FV-2030" {
+		t.Errorf("Excepted FV-2030, got %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,178 @@
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Locale is the formatting of the amounts and the quantities
+type Locale struct {
+	Name string
+	// Decimal and Group are the decimal and the grouping separators
+	Decimal string
+	Group   string
+	// Symbol is the currency symbol placed before or after the amount
+	Symbol       string
+	SymbolBefore bool
+	SymbolSpace  bool
+	// Decimals is the number of the digits of the minor units
+	Decimals int
+	// Times separates the quantity and the price
+	Times string
+}
+
+// Locales
+var (
+	// Default formats the amounts without the currency symbol and the grouping
+	Default = Locale{Name: "", Decimal: ".", Decimals: 2, Times: " x "}
+
+	RU = Locale{Name: "ru-RU", Decimal: ",", Group: " ", Symbol: "₽", SymbolSpace: true, Decimals: 2, Times: " x "}
+	US = Locale{Name: "en-US", Decimal: ".", Group: ",", Symbol: "$", SymbolBefore: true, Decimals: 2, Times: " x "}
+	GB = Locale{Name: "en-GB", Decimal: ".", Group: ",", Symbol: "£", SymbolBefore: true, Decimals: 2, Times: " x "}
+	DE = Locale{Name: "de-DE", Decimal: ",", Group: ".", Symbol: "€", SymbolSpace: true, Decimals: 2, Times: " x "}
+	FR = Locale{Name: "fr-FR", Decimal: ",", Group: " ", Symbol: "€", SymbolSpace: true, Decimals: 2, Times: " x "}
+	UA = Locale{Name: "uk-UA", Decimal: ",", Group: " ", Symbol: "₴", SymbolSpace: true, Decimals: 2, Times: " x "}
+	KZ = Locale{Name: "kk-KZ", Decimal: ",", Group: " ", Symbol: "₸", SymbolSpace: true, Decimals: 2, Times: " x "}
+	IL = Locale{Name: "he-IL", Decimal: ".", Group: ",", Symbol: "₪", SymbolSpace: true, Decimals: 2, Times: " x "}
+	JP = Locale{Name: "ja-JP", Decimal: ".", Group: ",", Symbol: "¥", SymbolBefore: true, Decimals: 0, Times: " x "}
+)
+
+var locales = []Locale{RU, US, GB, DE, FR, UA, KZ, IL, JP}
+
+// Lookup returns the locale by the name: "ru-RU", "ru_RU" or the language "ru"
+func Lookup(name string) (Locale, bool) {
+	name = strings.Replace(name, "_", "-", -1)
+	for _, l := range locales {
+		if strings.EqualFold(l.Name, name) {
+			return l, true
+		}
+	}
+	for _, l := range locales {
+		if lang := strings.SplitN(l.Name, "-", 2)[0]; strings.EqualFold(lang, name) {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Number formats the amount in the minor units without the currency symbol
+func (l Locale) Number(minor int64, group bool) string {
+	neg := minor < 0
+	if neg {
+		minor = -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if len(digits) <= l.Decimals {
+		digits = strings.Repeat("0", l.Decimals-len(digits)+1) + digits
+	}
+	intpart, frac := digits[:len(digits)-l.Decimals], digits[len(digits)-l.Decimals:]
+	if group && l.Group != "" {
+		intpart = groupDigits(intpart, l.Group)
+	}
+	result := intpart
+	if frac != "" {
+		result += l.Decimal + frac
+	}
+	if neg {
+		result = "-" + result
+	}
+	return result
+}
+
+func groupDigits(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Amount formats the amount in the minor units (e.g. cents) with the currency symbol
+func (l Locale) Amount(minor int64) string {
+	return l.amount(minor, true, true)
+}
+
+func (l Locale) amount(minor int64, group, symbol bool) string {
+	number := l.Number(minor, group)
+	if !symbol || l.Symbol == "" {
+		return number
+	}
+	space := ""
+	if l.SymbolSpace {
+		space = " "
+	}
+	if l.SymbolBefore {
+		if strings.HasPrefix(number, "-") {
+			return "-" + l.Symbol + space + number[1:]
+		}
+		return l.Symbol + space + number
+	}
+	return number + space + l.Symbol
+}
+
+// Fit formats the amount to fit in the width, dropping the grouping
+// and then the currency symbol. It reports false if the amount does not fit.
+func (l Locale) Fit(minor int64, width int) (string, bool) {
+	for _, v := range [][2]bool{{true, true}, {false, true}, {false, false}} {
+		s := l.amount(minor, v[0], v[1])
+		if utf8.RuneCountInString(s) <= width {
+			return s, true
+		}
+	}
+	return l.amount(minor, false, false), false
+}
+
+// Quantity formats the quantity with up to 3 decimals, the trailing zeros are dropped
+func (l Locale) Quantity(q float64) string {
+	s := strconv.FormatFloat(q, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return strings.Replace(s, ".", l.Decimal, 1)
+}
+
+// Total returns the amount of the quantity at the price in the minor units
+func Total(q float64, price int64) int64 {
+	return int64(math.Round(q * float64(price)))
+}
+
+// Line formats the quantity times the price on the left and the total
+// on the right of the width, e.g. "2 x $10.00    $20.00". The grouping, the currency
+// symbols and then the price with the times sign are dropped until the line fits,
+// e.g. "2    $20.00".
+func (l Locale) Line(q float64, price int64, width int) string {
+	total := Total(q, price)
+	qty := l.Quantity(q)
+	for _, v := range [][3]bool{
+		{true, true, true},
+		{false, true, true},
+		{false, false, true},
+		{false, false, false},
+	} {
+		left := qty + l.Times + l.amount(price, v[0], v[1])
+		if !v[2] {
+			// the times sign without the price would read as the price
+			left = qty
+		}
+		right := l.amount(total, v[0], v[1])
+		pad := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+		if pad >= 1 {
+			return left + strings.Repeat(" ", pad) + right
+		}
+	}
+	right, _ := l.Fit(total, width)
+	if pad := width - utf8.RuneCountInString(right); pad > 0 {
+		right = strings.Repeat(" ", pad) + right
+	}
+	return right
+}
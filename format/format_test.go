@@ -0,0 +1,84 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestAmount(t *testing.T) {
+	cases := []struct {
+		Locale Locale
+		Minor  int64
+		Want   string
+	}{
+		{RU, 123456789, "1 234 567,89 ₽"},
+		{US, 123456789, "$1,234,567.89"},
+		{US, -1050, "-$10.50"},
+		{DE, 100000, "1.000,00 €"},
+		{DE, 5, "0,05 €"},
+		{JP, 1500, "¥1,500"},
+		{Default, 12350, "123.50"},
+	}
+	for _, c := range cases {
+		if got := c.Locale.Amount(c.Minor); got != c.Want {
+			t.Errorf("%s: Amount(%d) excepted %q, got %q", c.Locale.Name, c.Minor, c.Want, got)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	cases := []struct {
+		Width int
+		Want  string
+		Fit   bool
+	}{
+		{14, "1 234 567,89 ₽", true},
+		{13, "1234567,89 ₽", true},
+		{10, "1234567,89", true},
+		{5, "1234567,89", false},
+	}
+	for _, c := range cases {
+		got, fit := RU.Fit(123456789, c.Width)
+		if got != c.Want || fit != c.Fit {
+			t.Errorf("Fit(%d) excepted %q,%v, got %q,%v", c.Width, c.Want, c.Fit, got, fit)
+		}
+	}
+}
+
+func TestQuantityAndLine(t *testing.T) {
+	if got := DE.Quantity(1.250); got != "1,25" {
+		t.Errorf("Excepted %q, got %q", "1,25", got)
+	}
+	if got := US.Quantity(2); got != "2" {
+		t.Errorf("Excepted %q, got %q", "2", got)
+	}
+	if got := Total(1.5, 999); got != 1499 {
+		t.Errorf("Excepted total 1499, got %d", got)
+	}
+
+	cases := []struct {
+		Locale Locale
+		Width  int
+		Want   string
+	}{
+		{US, 20, "2 x 1250.00  2500.00"},
+		{US, 30, "2 x $1,250.00        $2,500.00"},
+		{RU, 20, "2 x 1250,00  2500,00"},
+		{RU, 12, "2    2500,00"},
+	}
+	for _, c := range cases {
+		if got := c.Locale.Line(2, 125000, c.Width); got != c.Want {
+			t.Errorf("%s: Line(%d) excepted %q, got %q", c.Locale.Name, c.Width, c.Want, got)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"ru-RU", "ru_ru", "ru"} {
+		if l, ok := Lookup(name); !ok || l.Name != "ru-RU" {
+			t.Errorf("Lookup(%q) excepted ru-RU, got %q,%v", name, l.Name, ok)
+		}
+	}
+	if _, ok := Lookup("xx-XX"); ok {
+		t.Error("Excepted unknown locale")
+	}
+}
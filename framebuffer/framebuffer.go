@@ -0,0 +1,169 @@
+package framebuffer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/arteev/gold/driver"
+)
+
+// moveCost is the length in bytes of a cursor move command. Unchanged cells
+// shorter than the move are rewritten instead of being skipped.
+const moveCost = 4
+
+// Buffer is an in-memory copy of a line display. The text is written
+// to the buffer and Flush sends to the display only the changed cells.
+type Buffer struct {
+	mu   sync.Mutex
+	dsp  driver.Display
+	rows int
+	cols int
+
+	want   [][]rune
+	glass  [][]rune
+	synced bool
+}
+
+// New returns a blank buffer of the display with the rows x cols geometry
+func New(dsp driver.Display, rows, cols int) *Buffer {
+	return &Buffer{
+		dsp:   dsp,
+		rows:  rows,
+		cols:  cols,
+		want:  blank(rows, cols),
+		glass: blank(rows, cols),
+	}
+}
+
+func blank(rows, cols int) [][]rune {
+	lines := make([][]rune, rows)
+	for i := range lines {
+		lines[i] = []rune(strings.Repeat(" ", cols))
+	}
+	return lines
+}
+
+// Rows returns the number of rows of the buffer
+func (b *Buffer) Rows() int {
+	return b.rows
+}
+
+// Cols returns the number of columns of the buffer
+func (b *Buffer) Cols() int {
+	return b.cols
+}
+
+// PrintRow replaces the row (1-based) with the text padded with spaces.
+// The text is truncated to the width of the buffer.
+func (b *Buffer) PrintRow(row byte, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	line := b.line(row)
+	if line == nil {
+		return
+	}
+	for i := range line {
+		line[i] = ' '
+	}
+	copy(line, []rune(text))
+}
+
+// WriteAt writes the text from the position (1-based) to the end of the row
+func (b *Buffer) WriteAt(row, col byte, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	line := b.line(row)
+	if line == nil || col < 1 || int(col) > b.cols {
+		return
+	}
+	copy(line[col-1:], []rune(text))
+}
+
+// Clear fills the buffer with spaces
+func (b *Buffer) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.want = blank(b.rows, b.cols)
+}
+
+// Invalidate forgets the content of the display, the next Flush clears
+// the display and writes the whole buffer.
+func (b *Buffer) Invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.synced = false
+}
+
+func (b *Buffer) line(row byte) []rune {
+	if row < 1 || int(row) > b.rows {
+		return nil
+	}
+	return b.want[row-1]
+}
+
+// Flush brings the display in sync with the buffer by moving the cursor
+// to the changed parts of the rows and writing only them.
+func (b *Buffer) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.synced {
+		if err := b.dsp.Clear(); err != nil {
+			return err
+		}
+		b.glass = blank(b.rows, b.cols)
+		b.synced = true
+	}
+	for r := 0; r < b.rows; r++ {
+		for _, run := range diff(b.glass[r], b.want[r]) {
+			text := string(b.want[r][run.from:run.to])
+			err := b.dsp.CursorMove(byte(r+1), byte(run.from+1))
+			if err == nil {
+				err = b.dsp.Print(text)
+			}
+			if err != nil {
+				// the cursor and the glass are unknown after a failed write
+				b.synced = false
+				return err
+			}
+			copy(b.glass[r][run.from:run.to], b.want[r][run.from:run.to])
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the rows as they are shown on the display
+func (b *Buffer) Snapshot() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make([]string, b.rows)
+	for i, line := range b.glass {
+		result[i] = string(line)
+	}
+	return result
+}
+
+type run struct {
+	from, to int
+}
+
+// diff returns the ranges of the cells to rewrite. The changed ranges
+// separated by less than moveCost unchanged cells are merged.
+func diff(glass, want []rune) []run {
+	var runs []run
+	for i := 0; i < len(want); i++ {
+		if glass[i] == want[i] {
+			continue
+		}
+		j := i + 1
+		for j < len(want) && glass[j] != want[j] {
+			j++
+		}
+		if n := len(runs); n > 0 && i-runs[n-1].to < moveCost {
+			runs[n-1].to = j
+		} else {
+			runs = append(runs, run{i, j})
+		}
+		i = j
+	}
+	return runs
+}
@@ -0,0 +1,123 @@
+package framebuffer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockDisplay struct {
+	driver.Display
+	calls   []string
+	PrintFn func(string) error
+}
+
+func (m *mockDisplay) Clear() error {
+	m.calls = append(m.calls, "Clear")
+	return nil
+}
+
+func (m *mockDisplay) CursorMove(row, col byte) error {
+	m.calls = append(m.calls, fmt.Sprintf("CursorMove(%d,%d)", row, col))
+	return nil
+}
+
+func (m *mockDisplay) Print(text string) error {
+	m.calls = append(m.calls, fmt.Sprintf("Print(%q)", text))
+	if m.PrintFn != nil {
+		return m.PrintFn(text)
+	}
+	return nil
+}
+
+func TestFlush(t *testing.T) {
+	dsp := &mockDisplay{}
+	b := New(dsp, 2, 20)
+	b.PrintRow(1, "Price:10$ Quant:2")
+	b.PrintRow(2, "Total:20$")
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Clear",
+		"CursorMove(1,1)", `Print("Price:10$ Quant:2")`,
+		"CursorMove(2,1)", `Print("Total:20$")`,
+	}
+	if !reflect.DeepEqual(dsp.calls, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.calls)
+	}
+
+	dsp.calls = nil
+	b.PrintRow(1, "Price:12$ Quant:3")
+	b.WriteAt(2, 7, "36$")
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"CursorMove(1,8)", `Print("2")`,
+		"CursorMove(1,17)", `Print("3")`,
+		"CursorMove(2,7)", `Print("36")`,
+	}
+	if !reflect.DeepEqual(dsp.calls, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.calls)
+	}
+
+	dsp.calls = nil
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(dsp.calls) != 0 {
+		t.Errorf("Excepted nothing to flush, got %q", dsp.calls)
+	}
+
+	wantSnapshot := []string{
+		"Price:12$ Quant:3   ",
+		"Total:36$           ",
+	}
+	if got := b.Snapshot(); !reflect.DeepEqual(got, wantSnapshot) {
+		t.Errorf("Excepted snapshot %q, got %q", wantSnapshot, got)
+	}
+}
+
+func TestFlushError(t *testing.T) {
+	errtest := errors.New("fake")
+	dsp := &mockDisplay{
+		PrintFn: func(string) error { return errtest },
+	}
+	b := New(dsp, 2, 20)
+	b.PrintRow(1, "Total")
+	if err := b.Flush(); err != errtest {
+		t.Fatalf("Excepted %v, got %v", errtest, err)
+	}
+	dsp.PrintFn = nil
+	dsp.calls = nil
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(dsp.calls) == 0 || dsp.calls[0] != "Clear" {
+		t.Errorf("Excepted redraw after error, got %q", dsp.calls)
+	}
+	if got := b.Snapshot()[0]; got != "Total               " {
+		t.Errorf("Excepted %q, got %q", "Total", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		glass, want string
+		runs        []run
+	}{
+		{"abcdef", "abcdef", nil},
+		{"abcdef", "xbcdef", []run{{0, 1}}},
+		{"abcdefgh", "xbcxefgh", []run{{0, 4}}},
+		{"abcdefghij", "xbcdefghix", []run{{0, 1}, {9, 10}}},
+	}
+	for _, c := range cases {
+		if got := diff([]rune(c.glass), []rune(c.want)); !reflect.DeepEqual(got, c.runs) {
+			t.Errorf("diff(%q,%q) excepted %v, got %v", c.glass, c.want, c.runs, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package glyph
+
+import "sync"
+
+// Width and Height of the glyphs of the user-defined characters (5x7 font)
+const (
+	Width  = 5
+	Height = 7
+)
+
+var (
+	muGlyphs sync.RWMutex
+	glyphs   = make(map[rune][]byte)
+)
+
+// FromRows returns the glyph drawn by the rows of '#' (dot) and any other
+// byte (blank). The glyph is Width columns from left to right,
+// the bit 0 of a column is the top row.
+func FromRows(rows ...string) []byte {
+	glyph := make([]byte, Width)
+	for y, row := range rows {
+		if y >= Height {
+			break
+		}
+		for x := 0; x < len(row) && x < Width; x++ {
+			if row[x] == '#' {
+				glyph[x] |= 1 << uint(y)
+			}
+		}
+	}
+	return glyph
+}
+
+// Register adds the glyph of the rune to the library. A glyph of the rune
+// registered before is replaced.
+func Register(r rune, glyph []byte) {
+	muGlyphs.Lock()
+	defer muGlyphs.Unlock()
+	if len(glyph) != Width {
+		panic("glyph: Register glyph must have 5 columns")
+	}
+	glyphs[r] = append([]byte(nil), glyph...)
+}
+
+// Lookup returns the glyph of the rune
+func Lookup(r rune) ([]byte, bool) {
+	muGlyphs.RLock()
+	defer muGlyphs.RUnlock()
+	glyph, ok := glyphs[r]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), glyph...), true
+}
+
+func init() {
+	Register('₽', FromRows(
+		".###.",
+		".#..#",
+		".#..#",
+		"####.",
+		".#...",
+		"###..",
+		".#...",
+	))
+	Register('€', FromRows(
+		"..###",
+		".#...",
+		"####.",
+		".#...",
+		"####.",
+		".#...",
+		"..###",
+	))
+	Register('₴', FromRows(
+		".###.",
+		"#...#",
+		"#####",
+		"..#..",
+		"#####",
+		"#...#",
+		".###.",
+	))
+	Register('₸', FromRows(
+		"#####",
+		".....",
+		"#####",
+		"..#..",
+		"..#..",
+		"..#..",
+		"..#..",
+	))
+	Register('№', FromRows(
+		"#..#.",
+		"##.#.",
+		"#.##.",
+		"#..#.",
+		"#..#.",
+		"....#",
+		"...##",
+	))
+	Register('✓', FromRows(
+		".....",
+		"....#",
+		"...##",
+		"#.##.",
+		"###..",
+		".#...",
+		".....",
+	))
+	Register('✗', FromRows(
+		".....",
+		"#...#",
+		".#.#.",
+		"..#..",
+		".#.#.",
+		"#...#",
+		".....",
+	))
+}
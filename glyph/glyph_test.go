@@ -0,0 +1,45 @@
+package glyph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFromRows(t *testing.T) {
+	got := FromRows(
+		"#...#",
+		".#...",
+		"..#..",
+		".....",
+		".....",
+		".....",
+		"#####",
+	)
+	want := []byte{0x41, 0x42, 0x44, 0x40, 0x41}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Excepted %x, got %x", want, got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	g := FromRows("#")
+	Register('¤', g)
+	got, ok := Lookup('¤')
+	if !ok || !bytes.Equal(got, g) {
+		t.Errorf("Excepted %x, got %x", g, got)
+	}
+	got[0] = 0xff
+	if again, _ := Lookup('¤'); again[0] != 1 {
+		t.Error("Excepted a copy of the glyph")
+	}
+	if _, ok := Lookup('a'); ok {
+		t.Error("Excepted no glyph of 'a'")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Excepted panic of a short glyph")
+		}
+	}()
+	Register('¤', []byte{1})
+}
@@ -0,0 +1,59 @@
+package goldtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+)
+
+// RowReader is the screen of Display or emulator.Emulator
+type RowReader interface {
+	Row(row int) string
+}
+
+// AssertCalls checks the commands are exactly the wanted ones,
+// e.g. `PrintRow(1, "Total:20$")` as printed by driver.Command
+func AssertCalls(t testing.TB, got []driver.Command, want ...string) {
+	t.Helper()
+	names := commandStrings(got)
+	if strings.Join(names, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Excepted calls\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(names, "\n"))
+	}
+}
+
+// AssertCallsContain checks the commands contain the wanted ones in the order
+// with any commands between them
+func AssertCallsContain(t testing.TB, got []driver.Command, want ...string) {
+	t.Helper()
+	names := commandStrings(got)
+	i := 0
+	for _, name := range names {
+		if i < len(want) && name == want[i] {
+			i++
+		}
+	}
+	if i < len(want) {
+		t.Errorf("Excepted call %s after\n%s\ngot\n%s", want[i], strings.Join(want[:i], "\n"), strings.Join(names, "\n"))
+	}
+}
+
+// AssertRows checks the rows of the screen from the first one,
+// the trailing spaces are ignored
+func AssertRows(t testing.TB, screen RowReader, want ...string) {
+	t.Helper()
+	for i, text := range want {
+		text = strings.TrimRight(text, " ")
+		if got := screen.Row(i + 1); got != text {
+			t.Errorf("Excepted row %d %q, got %q", i+1, text, got)
+		}
+	}
+}
+
+func commandStrings(cmds []driver.Command) []string {
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.String()
+	}
+	return names
+}
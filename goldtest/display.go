@@ -0,0 +1,246 @@
+package goldtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/emulator"
+	"golang.org/x/text/encoding"
+)
+
+// Display is the driver.Display recording the calls and keeping the screen
+// of the emulator. The calls are recorded as the commands named by the methods
+// of driver.Display with the arguments as passed, except SetTime
+// recorded as SetTime(hour, minute) like the decoded commands.
+type Display struct {
+	mu        sync.Mutex
+	emu       *emulator.Emulator
+	encoding  encoding.Encoding
+	calls     []driver.Command
+	fail      map[string]error
+	status    driver.Status
+	answer    []byte
+	closed    bool
+	observers []driver.Observer
+}
+
+// NewDisplay returns the display of the size, e.g. 2 rows of 20 characters
+func NewDisplay(rows, cols int) *Display {
+	return &Display{
+		emu:    emulator.New(nil, rows, cols),
+		fail:   make(map[string]error),
+		status: driver.Status{Online: true},
+	}
+}
+
+// Fail makes the command fail with the error until Fail(name, nil), e.g.
+//
+//	dsp.Fail("Reverse", &driver.ProtocolError{Command: "Reverse", Err: driver.ErrNotSupported})
+//
+// The failed calls are recorded but do not change the screen.
+func (d *Display) Fail(name string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil {
+		delete(d.fail, name)
+		return
+	}
+	d.fail[name] = err
+}
+
+// Respond adds the bytes returned by Receive
+func (d *Display) Respond(b []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.answer = append(d.answer, b...)
+}
+
+// SetStatus sets the status returned by Status, Online by default
+func (d *Display) SetStatus(status driver.Status) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status = status
+}
+
+// Calls returns the recorded calls
+func (d *Display) Calls() []driver.Command {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]driver.Command(nil), d.calls...)
+}
+
+// Reset clears the recorded calls, the screen is left as is
+func (d *Display) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = nil
+}
+
+// Screen returns the copy of the screen
+func (d *Display) Screen() emulator.Screen {
+	return d.emu.Screen()
+}
+
+// Row returns the text of the row without the trailing spaces
+func (d *Display) Row(row int) string {
+	return d.emu.Row(row)
+}
+
+// Closed reports whether the display is closed
+func (d *Display) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// AddObserver adds the observer of the calls
+func (d *Display) AddObserver(o driver.Observer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.observers = append(d.observers, o)
+}
+
+// call records the command and applies the screen command unless the call fails
+func (d *Display) call(cmd, screen driver.Command, err error) error {
+	d.mu.Lock()
+	d.calls = append(d.calls, cmd)
+	if err == nil {
+		err = d.fail[cmd.Name]
+	}
+	if err == nil && d.closed {
+		err = driver.ErrNotOpen
+	}
+	if err == nil {
+		d.emu.Apply(screen)
+	}
+	observers := d.observers
+	d.mu.Unlock()
+	d.notify(driver.Event{Time: time.Now(), Command: cmd.Name, Args: cmd.Args, Err: err}, observers)
+	return err
+}
+
+func (d *Display) notify(e driver.Event, observers []driver.Observer) {
+	for _, o := range observers {
+		o.Observe(e)
+	}
+}
+
+func (d *Display) do(name string, args ...interface{}) error {
+	cmd := driver.Command{Name: name, Args: args}
+	return d.call(cmd, cmd, nil)
+}
+
+// text records the command with the text and applies it encoded
+func (d *Display) text(name string, text string, args ...interface{}) error {
+	cmd := driver.Command{Name: name, Args: append(append([]interface{}(nil), args...), text)}
+	d.mu.Lock()
+	enc := d.encoding
+	d.mu.Unlock()
+	encoded := text
+	var err error
+	if enc != nil {
+		if encoded, err = enc.NewEncoder().String(text); err != nil {
+			err = &driver.ProtocolError{Command: name, Err: driver.ErrEncoding}
+		}
+	}
+	return d.call(cmd, driver.Command{Name: name, Args: append(append([]interface{}(nil), args...), encoded)}, err)
+}
+
+func (d *Display) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, driver.Command{Name: "Close"})
+	if d.closed {
+		return driver.ErrNotOpen
+	}
+	d.closed = true
+	return nil
+}
+
+// SetEncoding sets the encoding of the text of the screen. Nil means ASCII.
+func (d *Display) SetEncoding(enc encoding.Encoding) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.encoding = enc
+	d.emu.SetEncoding(enc)
+}
+
+func (d *Display) Init() error  { return d.do("Init") }
+func (d *Display) Test() error  { return d.do("Test") }
+func (d *Display) Clear() error { return d.do("Clear") }
+
+func (d *Display) Send(data []byte) error {
+	return d.do("Send", append([]byte(nil), data...))
+}
+
+// Receive returns the bytes set by Respond or driver.ErrTimeout if there are none
+func (d *Display) Receive(b []byte) (n int, err error) {
+	if err := d.do("Receive"); err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.answer) == 0 {
+		return 0, driver.ErrTimeout
+	}
+	n = copy(b, d.answer)
+	d.answer = d.answer[n:]
+	return n, nil
+}
+
+func (d *Display) ModeRewrite() error { return d.do("ModeRewrite") }
+func (d *Display) ModeVScroll() error { return d.do("ModeVScroll") }
+func (d *Display) ModeHScroll() error { return d.do("ModeHScroll") }
+
+func (d *Display) Brightness(value byte) error { return d.do("Brightness", value) }
+
+func (d *Display) ClearRow() error { return d.do("ClearRow") }
+
+func (d *Display) CursorVisible(visible bool) error { return d.do("CursorVisible", visible) }
+func (d *Display) CursorMoveUp() error              { return d.do("CursorMoveUp") }
+func (d *Display) CursorMoveDown() error            { return d.do("CursorMoveDown") }
+func (d *Display) CursorMoveRight() error           { return d.do("CursorMoveRight") }
+func (d *Display) CursorMoveLeft() error            { return d.do("CursorMoveLeft") }
+func (d *Display) CursorMoveLeftTop() error         { return d.do("CursorMoveLeftTop") }
+func (d *Display) CursorMoveBeginInRow() error      { return d.do("CursorMoveBeginInRow") }
+func (d *Display) CursorMoveEndInRow() error        { return d.do("CursorMoveEndInRow") }
+func (d *Display) CursorMoveBottom() error          { return d.do("CursorMoveBottom") }
+func (d *Display) CursorMove(row, col byte) error   { return d.do("CursorMove", row, col) }
+
+func (d *Display) PrintRow(row byte, text string) error { return d.text("PrintRow", text, row) }
+func (d *Display) Print(text string) error              { return d.text("Print", text) }
+func (d *Display) CharSize(width, height byte) error    { return d.do("CharSize", width, height) }
+
+func (d *Display) FlagEnable(enabled bool, num byte) error { return d.do("FlagEnable", enabled, num) }
+func (d *Display) FlagsDisable() error                     { return d.do("FlagsDisable") }
+
+func (d *Display) SetTime(t time.Time) error {
+	return d.do("SetTime", byte(t.Hour()), byte(t.Minute()))
+}
+func (d *Display) ShowClock() error { return d.do("ShowClock") }
+
+func (d *Display) Blink(interval time.Duration) error { return d.do("Blink", interval) }
+func (d *Display) BlinkRow(row byte, interval time.Duration) error {
+	return d.do("BlinkRow", row, interval)
+}
+func (d *Display) Reverse(enabled bool) error   { return d.do("Reverse", enabled) }
+func (d *Display) Underline(enabled bool) error { return d.do("Underline", enabled) }
+
+func (d *Display) SetCodeTable(table byte) error { return d.do("SetCodeTable", table) }
+func (d *Display) SetCharset(charset byte) error { return d.do("SetCharset", charset) }
+
+func (d *Display) DefineChar(code byte, glyph []byte) error {
+	return d.do("DefineChar", code, append([]byte(nil), glyph...))
+}
+func (d *Display) UserChars(enabled bool) error { return d.do("UserChars", enabled) }
+
+// Status returns the status set by SetStatus
+func (d *Display) Status() (driver.Status, error) {
+	if err := d.do("Status"); err != nil {
+		return driver.Status{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status, nil
+}
@@ -0,0 +1,97 @@
+package goldtest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"github.com/arteev/gold/serial/com"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	_ driver.Display    = &Display{}
+	_ driver.Observable = &Display{}
+	_ com.Serialer      = &Serialer{}
+)
+
+func TestDisplay(t *testing.T) {
+	dsp := NewDisplay(2, 20)
+	dsp.SetEncoding(charmap.CodePage866)
+	notSupported := &driver.ProtocolError{Command: "Reverse", Err: driver.ErrNotSupported}
+	dsp.Fail("Reverse", notSupported)
+	var events []driver.Event
+	dsp.AddObserver(driver.ObserverFunc(func(e driver.Event) { events = append(events, e) }))
+
+	dsp.Init()
+	dsp.PrintRow(1, "Итого:20$")
+	dsp.CursorMove(2, 3)
+	dsp.Print("OK")
+	if err := dsp.Reverse(true); !errors.Is(err, driver.ErrNotSupported) {
+		t.Errorf("Excepted not supported, got %v", err)
+	}
+	dsp.SetTime(time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC))
+
+	AssertCalls(t, dsp.Calls(),
+		"Init()",
+		`PrintRow(1, "Итого:20$")`,
+		"CursorMove(2, 3)",
+		`Print("OK")`,
+		"Reverse(true)",
+		"SetTime(12, 30)",
+	)
+	AssertCallsContain(t, dsp.Calls(), "Init()", `Print("OK")`)
+	AssertRows(t, dsp, "Итого:20$", "  OK")
+	if len(events) != 6 || events[4].Err != notSupported {
+		t.Errorf("Excepted 6 events with the error of Reverse, got %v", events)
+	}
+
+	dsp.Close()
+	if err := dsp.Clear(); err != driver.ErrNotOpen {
+		t.Errorf("Excepted %v, got %v", driver.ErrNotOpen, err)
+	}
+	AssertRows(t, dsp, "Итого:20$")
+}
+
+func TestSerialer(t *testing.T) {
+	port := NewSerialer()
+	s := com.MustSerial(firich.FirichProtocol{})
+	s.SetTiming(driver.Timing{})
+	s.CreatePort(port)
+
+	port.ShortWrite(1)
+	var short *driver.ShortWriteError
+	if err := s.PrintRow(1, "Total"); !errors.As(err, &short) || short.Written != 1 {
+		t.Errorf("Excepted short write of 1 byte, got %v", err)
+	}
+	port.FailWrite(errors.New("port is gone"))
+	if err := s.Init(); err == nil || err.Error() != "port is gone" {
+		t.Errorf("Excepted error of the write, got %v", err)
+	}
+	if err := s.PrintRow(2, "Paid"); err != nil {
+		t.Fatal(err)
+	}
+
+	port.On([]byte{0x05}, []byte{0x06, 0x00})
+	answer, err := s.Query([]byte{0x05}, 2, time.Second)
+	if err != nil || string(answer) != "\x06\x00" {
+		t.Errorf("Excepted answer, got % x, %v", answer, err)
+	}
+	want := [][]byte{{0x1b}, []byte("\x1b\x51\x42Paid\r"), {0x05}}
+	if got := port.Writes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Excepted writes % x, got % x", want, got)
+	}
+
+	port.SetDelay(50*time.Millisecond, 0)
+	s.SetTimeouts(0, 10*time.Millisecond)
+	if err := s.Clear(); !errors.Is(err, driver.ErrTimeout) {
+		t.Errorf("Excepted timeout, got %v", err)
+	}
+
+	if err := s.Close(); err != nil || !port.Closed() {
+		t.Errorf("Excepted closed port, got %v", err)
+	}
+}
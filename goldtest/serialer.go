@@ -0,0 +1,187 @@
+package goldtest
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// Serialer is the scriptable port of com.Serial. The writes are recorded,
+// the reads return the replies and block until there is a reply or the
+// port is closed. The scripted failures apply to the next writes and reads
+// in the order they are added.
+type Serialer struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	writes     [][]byte
+	replies    []reply
+	unread     []byte
+	writeFails []writeFail
+	readFails  []error
+	writeDelay time.Duration
+	readDelay  time.Duration
+	closed     bool
+}
+
+type reply struct {
+	request, answer []byte
+}
+
+// writeFail is the error or the short write of the write
+type writeFail struct {
+	n   int
+	err error
+}
+
+// NewSerialer returns the port
+func NewSerialer() *Serialer {
+	s := &Serialer{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// On makes the port answer the request. The answer is read
+// after the write of the same bytes as the request.
+// Nil answer removes the answer of the request.
+func (s *Serialer) On(request, answer []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.replies {
+		if bytes.Equal(r.request, request) {
+			s.replies = append(s.replies[:i], s.replies[i+1:]...)
+			break
+		}
+	}
+	if answer != nil {
+		s.replies = append(s.replies, reply{append([]byte(nil), request...), append([]byte(nil), answer...)})
+	}
+}
+
+// Reply adds the bytes to read now
+func (s *Serialer) Reply(answer []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unread = append(s.unread, answer...)
+	s.cond.Broadcast()
+}
+
+// FailWrite makes the next write fail with the error, nothing is written
+func (s *Serialer) FailWrite(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeFails = append(s.writeFails, writeFail{err: err})
+}
+
+// ShortWrite makes the next write accept only n bytes without an error
+func (s *Serialer) ShortWrite(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeFails = append(s.writeFails, writeFail{n: n})
+}
+
+// FailRead makes the next read fail with the error
+func (s *Serialer) FailRead(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readFails = append(s.readFails, err)
+	s.cond.Broadcast()
+}
+
+// SetDelay sets the time every write and read takes, e.g. to test the timeouts
+func (s *Serialer) SetDelay(write, read time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeDelay, s.readDelay = write, read
+}
+
+// Writes returns the data of the writes
+func (s *Serialer) Writes() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writes := make([][]byte, len(s.writes))
+	for i, w := range s.writes {
+		writes[i] = append([]byte(nil), w...)
+	}
+	return writes
+}
+
+// Written returns all the bytes written
+func (s *Serialer) Written() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return bytes.Join(s.writes, nil)
+}
+
+// Closed reports whether the port is closed
+func (s *Serialer) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Serialer) Write(b []byte) (n int, err error) {
+	s.mu.Lock()
+	delay := s.writeDelay
+	s.mu.Unlock()
+	time.Sleep(delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n = len(b)
+	if len(s.writeFails) > 0 {
+		fail := s.writeFails[0]
+		s.writeFails = s.writeFails[1:]
+		if fail.err != nil {
+			return 0, fail.err
+		}
+		if fail.n < n {
+			n = fail.n
+		}
+	}
+	s.writes = append(s.writes, append([]byte(nil), b[:n]...))
+	for _, r := range s.replies {
+		if bytes.Equal(r.request, b[:n]) {
+			s.unread = append(s.unread, r.answer...)
+			s.cond.Broadcast()
+			break
+		}
+	}
+	return n, nil
+}
+
+func (s *Serialer) Read(b []byte) (n int, err error) {
+	s.mu.Lock()
+	delay := s.readDelay
+	s.mu.Unlock()
+	time.Sleep(delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.unread) == 0 && len(s.readFails) == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if len(s.readFails) > 0 {
+		err = s.readFails[0]
+		s.readFails = s.readFails[1:]
+		return 0, err
+	}
+	if len(s.unread) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(b, s.unread)
+	s.unread = s.unread[n:]
+	return n, nil
+}
+
+// Close closes the port, the blocked reads return io.EOF
+func (s *Serialer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+	return nil
+}
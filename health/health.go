@@ -0,0 +1,126 @@
+package health
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// Event is the result of the check of the display
+type Event struct {
+	Time   time.Time
+	Online bool
+	Status driver.Status
+	// Err is the error of the status request
+	Err error
+}
+
+// Checker requests the status of the display periodically
+// and reports the transitions between online and offline
+type Checker struct {
+	dsp      driver.Display
+	interval time.Duration
+
+	mu       sync.Mutex
+	onChange func(Event)
+	last     *Event
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// DefaultInterval is the interval of the checks used instead of the non-positive one
+const DefaultInterval = 5 * time.Second
+
+// New returns the checker of the display. A non-positive interval means DefaultInterval.
+func New(dsp driver.Display, interval time.Duration) *Checker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Checker{
+		dsp:      dsp,
+		interval: interval,
+	}
+}
+
+// OnChange sets the handler of the transitions. The first check is
+// reported as a transition too.
+func (c *Checker) OnChange(fn func(Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// Check requests the status of the display now
+func (c *Checker) Check() Event {
+	status, err := c.dsp.Status()
+	e := Event{
+		Time:   time.Now(),
+		Online: status.Online,
+		Status: status,
+		Err:    err,
+	}
+	c.mu.Lock()
+	changed := c.last == nil || c.last.Online != e.Online
+	c.last = &e
+	onChange := c.onChange
+	c.mu.Unlock()
+	if changed && onChange != nil {
+		onChange(e)
+	}
+	return e
+}
+
+// Last returns the result of the last check
+func (c *Checker) Last() (Event, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last == nil {
+		return Event{}, false
+	}
+	return *c.last, true
+}
+
+// Start checks the display and starts the periodic checks.
+// It returns driver.ErrNotSupported if the display has no status.
+func (c *Checker) Start() error {
+	if e := c.Check(); errors.Is(e.Err, driver.ErrNotSupported) {
+		return e.Err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return nil
+	}
+	c.stop, c.done = make(chan struct{}), make(chan struct{})
+	go c.run(c.stop, c.done)
+	return nil
+}
+
+// Stop stops the periodic checks
+func (c *Checker) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (c *Checker) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.Check()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"github.com/arteev/gold/goldtest"
+	"github.com/arteev/gold/serial/com"
+)
+
+type mockDisplay struct {
+	driver.Display
+	mu     sync.Mutex
+	online bool
+	err    error
+}
+
+func (m *mockDisplay) Status() (driver.Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return driver.Status{Online: m.online}, m.err
+}
+
+func (m *mockDisplay) set(online bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.online, m.err = online, err
+}
+
+func TestChecker(t *testing.T) {
+	dsp := &mockDisplay{online: true}
+	c := New(dsp, 5*time.Millisecond)
+	events := make(chan Event, 10)
+	c.OnChange(func(e Event) { events <- e })
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	next := func() Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("Excepted transition is not reported")
+		}
+		return Event{}
+	}
+	if e := next(); !e.Online {
+		t.Errorf("Excepted online, got %+v", e)
+	}
+	dsp.set(false, nil)
+	if e := next(); e.Online {
+		t.Errorf("Excepted offline, got %+v", e)
+	}
+	dsp.set(true, nil)
+	if e := next(); !e.Online {
+		t.Errorf("Excepted online, got %+v", e)
+	}
+	if e, ok := c.Last(); !ok || !e.Online {
+		t.Errorf("Excepted last check online, got %+v", e)
+	}
+}
+
+func TestNotSupported(t *testing.T) {
+	c := New(&mockDisplay{err: driver.ErrNotSupported}, time.Millisecond)
+	if err := c.Start(); err != driver.ErrNotSupported {
+		t.Errorf("Excepted %v, got %v", driver.ErrNotSupported, err)
+	}
+	c.Stop()
+}
+
+func TestFirichChecker(t *testing.T) {
+	status, _ := firich.FirichProtocol{}.StatusQuery()
+	identify, _ := firich.FirichProtocol{}.IdentifyQuery()
+	port := goldtest.NewSerialer()
+	port.On(status, []byte{0x12})
+	port.On(identify, []byte("_FV-2030\x00"))
+	s := com.MustSerial(firich.FirichProtocol{})
+	s.CreatePort(port)
+	s.SetTimeouts(20*time.Millisecond, 0)
+	defer s.Close()
+
+	c := New(s, time.Hour)
+	if e := c.Check(); !e.Online || e.Err != nil || e.Status.Model != "FV-2030" {
+		t.Errorf("Excepted online FV-2030, got %+v", e)
+	}
+	// the display is powered off
+	port.On(status, nil)
+	if e := c.Check(); e.Online || e.Err != nil {
+		t.Errorf("Excepted offline, got %+v", e)
+	}
+}
+
+func TestUnknownModel(t *testing.T) {
+	status, _ := firich.FirichProtocol{}.StatusQuery()
+	identify, _ := firich.FirichProtocol{}.IdentifyQuery()
+	port := goldtest.NewSerialer()
+	port.On(status, []byte{0x12})
+	port.On(identify, []byte("FV-2030\x00"))
+	s := com.MustSerial(firich.FirichProtocol{})
+	s.CreatePort(port)
+	s.SetTimeouts(20*time.Millisecond, 0)
+	defer s.Close()
+
+	c := New(s, time.Hour)
+	if e := c.Check(); !e.Online || e.Err != nil || e.Status.Model != "" {
+		t.Errorf("Excepted online unknown model, got %+v", e)
+	}
+	// the identification is not requested again
+	port.On(identify, []byte("_FV-2030\x00"))
+	if e := c.Check(); !e.Online || e.Status.Model != "" {
+		t.Errorf("Excepted online unknown model, got %+v", e)
+	}
+}
+
+func TestDefaultInterval(t *testing.T) {
+	c := New(&mockDisplay{online: true}, 0)
+	if c.interval != DefaultInterval {
+		t.Errorf("Excepted %v, got %v", DefaultInterval, c.interval)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+}
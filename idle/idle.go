@@ -0,0 +1,383 @@
+package idle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"golang.org/x/text/encoding"
+)
+
+// Transition is run before an item of the playlist is shown
+type Transition func(dsp driver.Display) error
+
+// Transitions
+var (
+	// Cut shows the next item over the current content
+	Cut Transition
+	// ClearScreen clears the display before the next item
+	ClearScreen Transition = func(dsp driver.Display) error {
+		return dsp.Clear()
+	}
+)
+
+// Item is a screen of the idle playlist. Show and Transition write
+// to the display passed to them, a write through the manager stops the playlist.
+type Item struct {
+	Show       func(dsp driver.Display) error
+	Duration   time.Duration
+	Transition Transition
+}
+
+// Manager is a display which plays the playlist when there are no writes
+// for the timeout. Any write through the manager stops the playlist
+// before the write reaches the display. The item being drawn is finished
+// before the write, the next items are not drawn.
+type Manager struct {
+	driver.Display
+
+	// wmu serialises the writes to the display: the items and the foreground writes
+	wmu      sync.Mutex
+	mu       sync.Mutex
+	timeout  time.Duration
+	playlist []Item
+	resume   func(driver.Display) error
+	onError  func(error)
+	now      func() time.Time
+
+	last     time.Time
+	playing  bool
+	gen      uint64
+	activity chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New returns a manager of the display
+func New(dsp driver.Display, timeout time.Duration, playlist ...Item) *Manager {
+	return &Manager{
+		Display:  dsp,
+		timeout:  timeout,
+		playlist: playlist,
+		now:      time.Now,
+		activity: make(chan struct{}, 1),
+	}
+}
+
+// OnResume sets the function called before the first write after the playlist, e.g. Clear
+func (m *Manager) OnResume(fn func(driver.Display) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resume = fn
+}
+
+// OnError sets the handler of the errors of the playlist
+func (m *Manager) OnError(fn func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = fn
+}
+
+// Start starts watching the inactivity
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.last = m.now()
+	m.stop, m.done = make(chan struct{}), make(chan struct{})
+	go m.run(m.stop, m.done)
+}
+
+// Stop stops the playlist and watching the inactivity
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.gen++
+	m.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Idle reports whether the playlist is playing
+func (m *Manager) Idle() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.playing
+}
+
+// Touch marks the activity without writing to the display
+func (m *Manager) Touch() error {
+	return m.foreground(func() error { return nil })
+}
+
+func (m *Manager) run(stop, done chan struct{}) {
+	defer close(done)
+	defer func() {
+		m.mu.Lock()
+		m.playing = false
+		m.mu.Unlock()
+	}()
+	for {
+		m.mu.Lock()
+		wait := m.timeout - m.now().Sub(m.last)
+		m.mu.Unlock()
+		if wait > 0 {
+			select {
+			case <-stop:
+				return
+			case <-m.activity:
+			case <-time.After(wait):
+			}
+			continue
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		m.play(stop)
+	}
+}
+
+// play shows the playlist in a loop until the activity
+func (m *Manager) play(stop chan struct{}) {
+	m.mu.Lock()
+	if m.now().Sub(m.last) < m.timeout {
+		m.mu.Unlock()
+		return
+	}
+	gen := m.gen
+	m.playing = true
+	m.mu.Unlock()
+
+	if len(m.playlist) == 0 {
+		select {
+		case <-stop:
+		case <-m.activity:
+		}
+		return
+	}
+	for i := 0; ; i = (i + 1) % len(m.playlist) {
+		item := m.playlist[i]
+		if !m.show(gen, item) {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-m.activity:
+			return
+		case <-time.After(item.Duration):
+		}
+	}
+}
+
+// show shows the item unless there was a foreground write since the playlist started
+func (m *Manager) show(gen uint64, item Item) bool {
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	m.mu.Lock()
+	if m.gen != gen {
+		m.mu.Unlock()
+		return false
+	}
+	onError := m.onError
+	m.mu.Unlock()
+
+	var err error
+	if item.Transition != nil {
+		err = item.Transition(m.Display)
+	}
+	if err == nil && item.Show != nil {
+		err = item.Show(m.Display)
+	}
+	if err != nil && onError != nil {
+		onError(err)
+	}
+	return true
+}
+
+// foreground stops the playlist and calls fn
+func (m *Manager) foreground(fn func() error) error {
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	m.mu.Lock()
+	m.last = m.now()
+	m.gen++
+	select {
+	case m.activity <- struct{}{}:
+	default:
+	}
+	var resume func(driver.Display) error
+	if m.playing {
+		m.playing = false
+		resume = m.resume
+	}
+	m.mu.Unlock()
+
+	if resume != nil {
+		if err := resume(m.Display); err != nil {
+			return err
+		}
+	}
+	return fn()
+}
+
+// Close stops the manager and closes the display
+func (m *Manager) Close() error {
+	m.Stop()
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	return m.Display.Close()
+}
+
+func (m *Manager) SetEncoding(enc encoding.Encoding) {
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	m.Display.SetEncoding(enc)
+}
+
+func (m *Manager) Init() error {
+	return m.foreground(m.Display.Init)
+}
+func (m *Manager) Test() error {
+	return m.foreground(m.Display.Test)
+}
+func (m *Manager) Clear() error {
+	return m.foreground(m.Display.Clear)
+}
+
+func (m *Manager) Send(data []byte) error {
+	return m.foreground(func() error { return m.Display.Send(data) })
+}
+func (m *Manager) Receive(b []byte) (n int, err error) {
+	err = m.foreground(func() error {
+		n, err = m.Display.Receive(b)
+		return err
+	})
+	return n, err
+}
+
+func (m *Manager) ModeRewrite() error {
+	return m.foreground(m.Display.ModeRewrite)
+}
+func (m *Manager) ModeVScroll() error {
+	return m.foreground(m.Display.ModeVScroll)
+}
+func (m *Manager) ModeHScroll() error {
+	return m.foreground(m.Display.ModeHScroll)
+}
+func (m *Manager) Brightness(value byte) error {
+	return m.foreground(func() error { return m.Display.Brightness(value) })
+}
+
+func (m *Manager) ClearRow() error {
+	return m.foreground(m.Display.ClearRow)
+}
+
+func (m *Manager) CursorVisible(visible bool) error {
+	return m.foreground(func() error { return m.Display.CursorVisible(visible) })
+}
+func (m *Manager) CursorMoveUp() error {
+	return m.foreground(m.Display.CursorMoveUp)
+}
+func (m *Manager) CursorMoveDown() error {
+	return m.foreground(m.Display.CursorMoveDown)
+}
+func (m *Manager) CursorMoveRight() error {
+	return m.foreground(m.Display.CursorMoveRight)
+}
+func (m *Manager) CursorMoveLeft() error {
+	return m.foreground(m.Display.CursorMoveLeft)
+}
+func (m *Manager) CursorMoveLeftTop() error {
+	return m.foreground(m.Display.CursorMoveLeftTop)
+}
+func (m *Manager) CursorMoveBeginInRow() error {
+	return m.foreground(m.Display.CursorMoveBeginInRow)
+}
+func (m *Manager) CursorMoveEndInRow() error {
+	return m.foreground(m.Display.CursorMoveEndInRow)
+}
+func (m *Manager) CursorMoveBottom() error {
+	return m.foreground(m.Display.CursorMoveBottom)
+}
+func (m *Manager) CursorMove(row, col byte) error {
+	return m.foreground(func() error { return m.Display.CursorMove(row, col) })
+}
+
+func (m *Manager) PrintRow(row byte, text string) error {
+	return m.foreground(func() error { return m.Display.PrintRow(row, text) })
+}
+func (m *Manager) Print(text string) error {
+	return m.foreground(func() error { return m.Display.Print(text) })
+}
+
+func (m *Manager) CharSize(width, height byte) error {
+	return m.foreground(func() error { return m.Display.CharSize(width, height) })
+}
+
+func (m *Manager) FlagEnable(enabled bool, num byte) error {
+	return m.foreground(func() error { return m.Display.FlagEnable(enabled, num) })
+}
+func (m *Manager) FlagsDisable() error {
+	return m.foreground(m.Display.FlagsDisable)
+}
+
+func (m *Manager) SetTime(t time.Time) error {
+	return m.foreground(func() error { return m.Display.SetTime(t) })
+}
+func (m *Manager) ShowClock() error {
+	return m.foreground(m.Display.ShowClock)
+}
+
+func (m *Manager) Blink(interval time.Duration) error {
+	return m.foreground(func() error { return m.Display.Blink(interval) })
+}
+func (m *Manager) BlinkRow(row byte, interval time.Duration) error {
+	return m.foreground(func() error { return m.Display.BlinkRow(row, interval) })
+}
+func (m *Manager) Reverse(enabled bool) error {
+	return m.foreground(func() error { return m.Display.Reverse(enabled) })
+}
+func (m *Manager) Underline(enabled bool) error {
+	return m.foreground(func() error { return m.Display.Underline(enabled) })
+}
+
+func (m *Manager) SetCodeTable(table byte) error {
+	return m.foreground(func() error { return m.Display.SetCodeTable(table) })
+}
+func (m *Manager) SetCharset(charset byte) error {
+	return m.foreground(func() error { return m.Display.SetCharset(charset) })
+}
+
+func (m *Manager) DefineChar(code byte, glyph []byte) error {
+	return m.foreground(func() error { return m.Display.DefineChar(code, glyph) })
+}
+func (m *Manager) UserChars(enabled bool) error {
+	return m.foreground(func() error { return m.Display.UserChars(enabled) })
+}
+
+// Status queries the device without the activity,
+// so the health checks do not stop the playlist
+func (m *Manager) Status() (driver.Status, error) {
+	return m.Display.Status()
+}
+
+// AddObserver adds the observer to the display if it reports the events
+func (m *Manager) AddObserver(o driver.Observer) {
+	if observable, ok := m.Display.(driver.Observable); ok {
+		observable.AddObserver(o)
+	}
+}
+
+// TextWidth returns the number of the cells of the text on the display
+func (m *Manager) TextWidth(text string) int {
+	return driver.TextWidth(m.Display, text)
+}
@@ -0,0 +1,169 @@
+package idle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockDisplay struct {
+	driver.Display
+	mu     sync.Mutex
+	rows   map[byte]string
+	clears int
+}
+
+func (m *mockDisplay) PrintRow(row byte, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[row] = text
+	return nil
+}
+
+func (m *mockDisplay) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clears++
+	m.rows = make(map[byte]string)
+	return nil
+}
+
+func (m *mockDisplay) row(row byte) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rows[row]
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Excepted condition is not met")
+}
+
+func TestPlaylist(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	promo := func(text string) func(driver.Display) error {
+		return func(d driver.Display) error {
+			return d.PrintRow(1, text)
+		}
+	}
+	m := New(dsp, 20*time.Millisecond,
+		Item{Show: promo("SALE -50%"), Duration: 10 * time.Millisecond, Transition: ClearScreen},
+		Item{Show: promo("NEW COFFEE"), Duration: 10 * time.Millisecond},
+	)
+	m.OnResume(func(d driver.Display) error { return d.Clear() })
+	m.Start()
+	defer m.Stop()
+
+	if err := m.PrintRow(1, "Total:20$"); err != nil {
+		t.Fatal(err)
+	}
+	if m.Idle() {
+		t.Fatal("Excepted not idle after the write")
+	}
+	waitFor(t, func() bool { return dsp.row(1) == "SALE -50%" })
+	waitFor(t, func() bool { return dsp.row(1) == "NEW COFFEE" })
+	if !m.Idle() {
+		t.Error("Excepted idle manager is playing")
+	}
+
+	if err := m.PrintRow(2, "Milk"); err != nil {
+		t.Fatal(err)
+	}
+	if m.Idle() {
+		t.Error("Excepted playlist stopped by the write")
+	}
+	if got := dsp.row(1); got != "" {
+		t.Errorf("Excepted the display cleared on resume, got %q", got)
+	}
+	if got := dsp.row(2); got != "Milk" {
+		t.Errorf("Excepted %q, got %q", "Milk", got)
+	}
+}
+
+func (m *mockDisplay) Status() (driver.Status, error) {
+	return driver.Status{Online: true}, nil
+}
+
+func TestForegroundWaitsForItem(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	drawing, release := make(chan struct{}), make(chan struct{})
+	m := New(dsp, 10*time.Millisecond, Item{
+		Show: func(d driver.Display) error {
+			if err := d.PrintRow(1, "PROMO"); err != nil {
+				return err
+			}
+			select {
+			case drawing <- struct{}{}:
+				<-release
+			default:
+			}
+			return d.PrintRow(2, "PROMO")
+		},
+		Duration:   time.Millisecond,
+		Transition: ClearScreen,
+	})
+	m.Start()
+	defer m.Stop()
+	select {
+	case <-drawing:
+	case <-time.After(time.Second):
+		t.Fatal("Excepted the item is shown")
+	}
+
+	written := make(chan error)
+	go func() {
+		written <- m.PrintRow(1, "Total:20$")
+	}()
+	select {
+	case <-written:
+		t.Fatal("Excepted the write waits for the item")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+	// the next items are not drawn over the write
+	time.Sleep(5 * time.Millisecond)
+	if got := dsp.row(1); got != "Total:20$" {
+		t.Errorf("Excepted %q, got %q", "Total:20$", got)
+	}
+}
+
+func TestStopClearsIdle(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	m := New(dsp, 10*time.Millisecond, Item{Show: func(driver.Display) error { return nil }, Duration: time.Hour})
+	m.Start()
+	waitFor(t, m.Idle)
+	m.Stop()
+	if m.Idle() {
+		t.Error("Excepted not idle after Stop")
+	}
+}
+
+func TestStatusIsNotActivity(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	m := New(dsp, 10*time.Millisecond, Item{Show: func(driver.Display) error { return nil }, Duration: time.Hour})
+	m.OnResume(func(d driver.Display) error { return d.Clear() })
+	m.Start()
+	defer m.Stop()
+	waitFor(t, m.Idle)
+	if _, err := m.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Idle() {
+		t.Error("Excepted Status does not stop the playlist")
+	}
+	dsp.mu.Lock()
+	defer dsp.mu.Unlock()
+	if dsp.clears != 0 {
+		t.Errorf("Excepted no resume, got %d clears", dsp.clears)
+	}
+}
@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// DefaultBuckets are the upper bounds of the write latency histogram in seconds
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Collector counts the events of the display. It is the driver.Observer
+// added to the display by AddObserver.
+type Collector struct {
+	mu           sync.Mutex
+	commands     map[string]uint64
+	errors       map[string]uint64
+	bytesWritten uint64
+	bytesRead    uint64
+	reconnects   uint64
+
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// New returns the collector with the latency histogram of the buckets.
+// No buckets means DefaultBuckets.
+func New(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Collector{
+		commands: make(map[string]uint64),
+		errors:   make(map[string]uint64),
+		buckets:  buckets,
+		counts:   make([]uint64, len(buckets)),
+	}
+}
+
+// Observe counts the event. The commands are counted by the writes,
+// so a query writing the request and reading the answer is counted once.
+func (c *Collector) Observe(e driver.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.Command == driver.Reconnect {
+		c.reconnects++
+		return
+	}
+	if e.Direction == driver.Write {
+		c.commands[e.Command]++
+	}
+	if e.Err != nil {
+		c.errors[Kind(e.Err)]++
+	}
+	if len(e.Data) == 0 {
+		return
+	}
+	if e.Direction == driver.Read {
+		c.bytesRead += uint64(len(e.Data))
+		return
+	}
+	c.bytesWritten += uint64(len(e.Data))
+	seconds := e.Duration.Seconds()
+	for i, le := range c.buckets {
+		if seconds <= le {
+			c.counts[i]++
+		}
+	}
+	c.sum += seconds
+	c.count++
+}
+
+// Reconnect counts the reopening of the port of the display. The reopening
+// by com.Serial.CreatePort is counted by Observe, Reconnect is called
+// by the callers reopening the display in another way.
+func (c *Collector) Reconnect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconnects++
+}
+
+// Kind returns the kind of the error used as the label of the error counter
+func Kind(err error) string {
+	switch {
+	case errors.Is(err, driver.ErrNotSupported):
+		return "not_supported"
+	case errors.Is(err, driver.ErrNotOpen):
+		return "not_open"
+	case errors.Is(err, driver.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, driver.ErrShortWrite):
+		return "short_write"
+	case errors.Is(err, driver.ErrInvalidRow):
+		return "invalid_row"
+	case errors.Is(err, driver.ErrEncoding):
+		return "encoding"
+	}
+	return "io"
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	c.mu.Lock()
+	writeCounters(&b, "gold_display_commands_total", "Commands sent to the display.", "command", c.commands)
+	writeCounters(&b, "gold_display_errors_total", "Errors of the display by kind.", "kind", c.errors)
+	writeCounter(&b, "gold_display_written_bytes_total", "Bytes written to the display.", c.bytesWritten)
+	writeCounter(&b, "gold_display_read_bytes_total", "Bytes read from the display.", c.bytesRead)
+	writeCounter(&b, "gold_display_reconnects_total", "Reconnects to the display.", c.reconnects)
+
+	name := "gold_display_write_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Latency of the writes to the display.\n# TYPE %s histogram\n", name, name)
+	for i, le := range c.buckets {
+		fmt.Fprintf(&b, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(le), c.counts[i])
+	}
+	fmt.Fprintf(&b, "%s_bucket{le=\"+Inf\"} %d\n", name, c.count)
+	fmt.Fprintf(&b, "%s_sum %s\n", name, formatFloat(c.sum))
+	fmt.Fprintf(&b, "%s_count %d\n", name, c.count)
+	c.mu.Unlock()
+	return b.WriteTo(w)
+}
+
+func writeCounter(b *bytes.Buffer, name, help string, value uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func writeCounters(b *bytes.Buffer, name, help, label string, values map[string]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%s{%s=\"%s\"} %d\n", name, label, escapeLabel(key), values[key])
+	}
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ServeHTTP writes the metrics for the Prometheus scraper
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteFile writes the metrics to the file read by the textfile collector
+// of the node exporter. The file is replaced atomically.
+func (c *Collector) WriteFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := c.WriteTo(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteFileEvery writes the metrics to the file with the interval until
+// the stop channel is closed. The errors are passed to onError if it is not nil.
+func (c *Collector) WriteFileEvery(path string, interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.WriteFile(path); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/firich"
+	"github.com/arteev/gold/goldtest"
+	"github.com/arteev/gold/serial/com"
+)
+
+func TestCollectQuery(t *testing.T) {
+	p := firich.FirichProtocol{}
+	status, _ := p.StatusQuery()
+	identify, _ := p.IdentifyQuery()
+	port := goldtest.NewSerialer()
+	port.On(status, []byte{0x12})
+	port.On(identify, []byte("_FV-2030\x00"))
+	s := com.MustSerial(p)
+	s.CreatePort(port)
+	s.SetTimeouts(20*time.Millisecond, 0)
+	defer s.Close()
+	c := New()
+	s.AddObserver(c)
+
+	if _, err := s.Status(); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`gold_display_commands_total{command="Identify"} 1`,
+		`gold_display_commands_total{command="Status"} 1`,
+		`gold_display_written_bytes_total 6`,
+		`gold_display_read_bytes_total 10`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("Excepted line %q in\n%s", line, b.String())
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := New(0.01, 0.1)
+	c.Observe(driver.Event{Command: "PrintRow", Data: []byte("abc"), Duration: 5 * time.Millisecond})
+	c.Observe(driver.Event{Command: "PrintRow", Data: []byte("de"), Duration: 50 * time.Millisecond})
+	c.Observe(driver.Event{Command: "Clear", Err: &driver.ProtocolError{Command: "Clear", Err: driver.ErrNotSupported}})
+	c.Observe(driver.Event{Command: "Status", Data: []byte{0x12}, Direction: driver.Read})
+	c.Observe(driver.Event{Command: "Send", Err: &com.TimeoutError{Op: "write"}})
+	c.Reconnect()
+	c.Observe(driver.Event{Command: driver.Reconnect})
+
+	var b bytes.Buffer
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`gold_display_commands_total{command="Clear"} 1`,
+		`gold_display_commands_total{command="PrintRow"} 2`,
+		`gold_display_errors_total{kind="not_supported"} 1`,
+		`gold_display_errors_total{kind="timeout"} 1`,
+		`gold_display_written_bytes_total 5`,
+		`gold_display_read_bytes_total 1`,
+		`gold_display_reconnects_total 2`,
+		`gold_display_write_duration_seconds_bucket{le="0.01"} 1`,
+		`gold_display_write_duration_seconds_bucket{le="0.1"} 2`,
+		`gold_display_write_duration_seconds_bucket{le="+Inf"} 2`,
+		`gold_display_write_duration_seconds_sum 0.055`,
+		`gold_display_write_duration_seconds_count 2`,
+		`# TYPE gold_display_write_duration_seconds histogram`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("Excepted line %q in\n%s", line, b.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Body.String() != b.String() || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Excepted metrics served by HTTP, got %q", rec.Body.String())
+	}
+
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gold.prom")
+	if err := c.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != b.String() {
+		t.Errorf("Excepted metrics written to the file, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,307 @@
+package protocoltest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// DefaultGlyph is the 5x7 glyph of DefineChar used when Caps.Glyph is empty
+var DefaultGlyph = []byte{0x7f, 0x41, 0x41, 0x41, 0x7f}
+
+// Caps are the capabilities of the protocol checked by Run
+type Caps struct {
+	// Unsupported are the names of the commands of driver.Display not
+	// supported by the protocol, e.g. "Reverse". Their commands must be empty.
+	Unsupported []string
+	// Rows and Cols are the size of the display. Zero means the size
+	// of driver.Sizer, the bounds are not checked if the size is unknown.
+	Rows, Cols byte
+	// Glyph is the glyph of DefineChar in the format of the protocol
+	Glyph []byte
+	// Golden is the directory of the golden files of the commands,
+	// e.g. "testdata". Empty means no golden files.
+	Golden string
+	// Update writes the golden files instead of comparing them,
+	// e.g. set by the -update flag of the test of the protocol
+	Update bool
+	// Decoder is the decoder of the protocol. If set, every command
+	// must be decoded back into the command of the same name.
+	Decoder driver.Decoder
+}
+
+// sample is the command of the protocol with the arguments
+type sample struct {
+	// Name is the name of the method of driver.Display
+	Name string
+	// Label is the name of the golden file
+	Label string
+	Cmd   func() []byte
+	// Text is the text the command must contain as is
+	Text string
+	// Args are the arguments the command is decoded with
+	Args []interface{}
+}
+
+// Run checks the commands of the protocol:
+//   - the supported commands are not empty, the unsupported ones are empty;
+//   - PrintRow is empty for the rows out of the display;
+//   - the text is copied byte by byte, not as UTF-8;
+//   - the commands are the same on every call and are not shared;
+//   - the commands match the golden files and are decoded back
+//     into the same names and arguments.
+func Run(t *testing.T, proto driver.Protocol, caps Caps) {
+	t.Helper()
+	unsupported, problems := unsupportedSet(caps.Unsupported)
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+	for _, s := range samples(proto, caps) {
+		s := s
+		t.Run(s.Label, func(t *testing.T) {
+			for _, problem := range check(s, unsupported[s.Name], caps.Decoder) {
+				t.Error(problem)
+			}
+			if caps.Golden != "" && !unsupported[s.Name] {
+				if err := golden(filepath.Join(caps.Golden, s.Label+".golden"), s.Cmd(), caps.Update); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	t.Run("Bounds", func(t *testing.T) {
+		for _, problem := range checkBounds(proto, caps) {
+			t.Error(problem)
+		}
+	})
+	t.Run("Capabilities", func(t *testing.T) {
+		for _, problem := range checkCapabilities(proto) {
+			t.Error(problem)
+		}
+	})
+}
+
+// names are the commands of driver.Display built by the protocol
+var names = []string{
+	"Init", "Clear", "Test", "ModeRewrite", "ModeVScroll", "ModeHScroll", "Brightness",
+	"ClearRow", "CursorVisible", "CursorMoveUp", "CursorMoveDown", "CursorMoveRight",
+	"CursorMoveLeft", "CursorMoveLeftTop", "CursorMoveBeginInRow", "CursorMoveEndInRow",
+	"CursorMoveBottom", "CursorMove", "PrintRow", "Print", "CharSize", "FlagEnable",
+	"FlagsDisable", "SetTime", "ShowClock", "Blink", "Reverse", "Underline",
+	"SetCodeTable", "SetCharset", "DefineChar", "UserChars",
+}
+
+func unsupportedSet(list []string) (map[string]bool, []string) {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	set := make(map[string]bool, len(list))
+	var problems []string
+	for _, name := range list {
+		if !known[name] {
+			problems = append(problems, fmt.Sprintf("Unknown unsupported command %q", name))
+		}
+		set[name] = true
+	}
+	return set, problems
+}
+
+func size(proto driver.Protocol, caps Caps) (rows, cols byte) {
+	rows, cols = caps.Rows, caps.Cols
+	if sizer, ok := proto.(driver.Sizer); ok && rows == 0 && cols == 0 {
+		rows, cols = sizer.Size()
+	}
+	return rows, cols
+}
+
+// encoded is the text in the 8-bit encoding which is not valid UTF-8
+const encoded = "\x91\xe3\xac\xac\xa0:\xff"
+
+func samples(p driver.Protocol, caps Caps) []sample {
+	rows, cols := size(p, caps)
+	glyph := caps.Glyph
+	if len(glyph) == 0 {
+		glyph = DefaultGlyph
+	}
+	list := []sample{
+		{Name: "Init", Cmd: p.InitCmd},
+		{Name: "Clear", Cmd: p.ClearCmd},
+		{Name: "Test", Cmd: p.TestCmd},
+		{Name: "ModeRewrite", Cmd: p.ModeRewriteCmd},
+		{Name: "ModeVScroll", Cmd: p.ModeVScrollCmd},
+		{Name: "ModeHScroll", Cmd: p.ModeHScrollCmd},
+		{Name: "Brightness", Label: "Brightness_1", Cmd: func() []byte { return p.BrightnessCmd(1) }, Args: args(byte(1))},
+		{Name: "Brightness", Label: "Brightness_4", Cmd: func() []byte { return p.BrightnessCmd(4) }, Args: args(byte(4))},
+		{Name: "ClearRow", Cmd: p.ClearRowCmd},
+		{Name: "CursorVisible", Label: "CursorVisible_true", Cmd: func() []byte { return p.CursorVisibleCmd(true) }, Args: args(true)},
+		{Name: "CursorVisible", Label: "CursorVisible_false", Cmd: func() []byte { return p.CursorVisibleCmd(false) }, Args: args(false)},
+		{Name: "CursorMoveUp", Cmd: p.CursorMoveUpCmd},
+		{Name: "CursorMoveDown", Cmd: p.CursorMoveDownCmd},
+		{Name: "CursorMoveRight", Cmd: p.CursorMoveRightCmd},
+		{Name: "CursorMoveLeft", Cmd: p.CursorMoveLeftCmd},
+		{Name: "CursorMoveLeftTop", Cmd: p.CursorMoveLeftTopCmd},
+		{Name: "CursorMoveBeginInRow", Cmd: p.CursorMoveBeginInRowCmd},
+		{Name: "CursorMoveEndInRow", Cmd: p.CursorMoveEndInRowCmd},
+		{Name: "CursorMoveBottom", Cmd: p.CursorMoveBottomCmd},
+		{Name: "CursorMove", Label: "CursorMove_1_1", Cmd: func() []byte { return p.CursorMoveCmd(1, 1) }, Args: args(byte(1), byte(1))},
+		{Name: "Print", Label: "Print", Cmd: func() []byte { return p.PrintCmd("Total:20$") }, Text: "Total:20$", Args: args("Total:20$")},
+		{Name: "Print", Label: "Print_encoded", Cmd: func() []byte { return p.PrintCmd(encoded) }, Text: encoded, Args: args(encoded)},
+		{Name: "CharSize", Label: "CharSize_2_2", Cmd: func() []byte { return p.CharSizeCmd(2, 2) }, Args: args(byte(2), byte(2))},
+		{Name: "FlagEnable", Label: "FlagEnable_true_1", Cmd: func() []byte { return p.FlagEnableCmd(true, 1) }, Args: args(true, byte(1))},
+		{Name: "FlagEnable", Label: "FlagEnable_false_1", Cmd: func() []byte { return p.FlagEnableCmd(false, 1) }, Args: args(false, byte(1))},
+		{Name: "FlagsDisable", Cmd: p.FlagsDisableCmd},
+		{Name: "SetTime", Label: "SetTime_12_30", Cmd: func() []byte { return p.SetTimeCmd(12, 30) }, Args: args(byte(12), byte(30))},
+		{Name: "ShowClock", Cmd: p.ShowClockCmd},
+		{Name: "Blink", Label: "Blink_500ms", Cmd: func() []byte { return p.BlinkCmd(500 * time.Millisecond) }, Args: args(500 * time.Millisecond)},
+		{Name: "Blink", Label: "Blink_0", Cmd: func() []byte { return p.BlinkCmd(0) }, Args: args(time.Duration(0))},
+		{Name: "Reverse", Label: "Reverse_true", Cmd: func() []byte { return p.ReverseCmd(true) }, Args: args(true)},
+		{Name: "Underline", Label: "Underline_true", Cmd: func() []byte { return p.UnderlineCmd(true) }, Args: args(true)},
+		{Name: "SetCodeTable", Label: "SetCodeTable_0", Cmd: func() []byte { return p.CodeTableCmd(0) }, Args: args(byte(0))},
+		{Name: "SetCharset", Label: "SetCharset_0", Cmd: func() []byte { return p.CharsetCmd(0) }, Args: args(byte(0))},
+		{Name: "DefineChar", Label: "DefineChar_f0", Cmd: func() []byte { return p.DefineCharCmd(0xf0, glyph) }, Args: args(byte(0xf0), glyph)},
+		{Name: "UserChars", Label: "UserChars_true", Cmd: func() []byte { return p.UserCharsCmd(true) }, Args: args(true)},
+		{Name: "UserChars", Label: "UserChars_false", Cmd: func() []byte { return p.UserCharsCmd(false) }, Args: args(false)},
+	}
+	if rows == 0 {
+		rows = 1
+	}
+	for row := byte(1); row <= rows; row++ {
+		row := row
+		list = append(list,
+			sample{Name: "PrintRow", Label: fmt.Sprintf("PrintRow_%d", row), Cmd: func() []byte { return p.PrintRowCmd(row, "Price:10$") }, Text: "Price:10$", Args: args(row, "Price:10$")},
+			sample{Name: "PrintRow", Label: fmt.Sprintf("PrintRow_%d_empty", row), Cmd: func() []byte { return p.PrintRowCmd(row, "") }, Args: args(row, "")},
+			sample{Name: "PrintRow", Label: fmt.Sprintf("PrintRow_%d_encoded", row), Cmd: func() []byte { return p.PrintRowCmd(row, encoded) }, Text: encoded, Args: args(row, encoded)},
+		)
+	}
+	if cols > 0 {
+		full := string(bytes.Repeat([]byte{'8'}, int(cols)))
+		list = append(list,
+			sample{Name: "CursorMove", Label: fmt.Sprintf("CursorMove_%d_%d", rows, cols), Cmd: func() []byte { return p.CursorMoveCmd(rows, cols) }, Args: args(rows, cols)},
+			sample{Name: "PrintRow", Label: "PrintRow_1_full", Cmd: func() []byte { return p.PrintRowCmd(1, full) }, Text: full, Args: args(byte(1), full)},
+		)
+	}
+	for i := range list {
+		if list[i].Label == "" {
+			list[i].Label = list[i].Name
+		}
+	}
+	return list
+}
+
+func args(values ...interface{}) []interface{} {
+	return values
+}
+
+// check returns the problems of the command of the sample
+func check(s sample, unsupported bool, decoder driver.Decoder) []string {
+	cmd := s.Cmd()
+	if unsupported {
+		if len(cmd) != 0 {
+			return []string{fmt.Sprintf("Excepted empty unsupported command, got % x", cmd)}
+		}
+		return nil
+	}
+	if len(cmd) == 0 {
+		return []string{"Excepted command, got empty"}
+	}
+	var problems []string
+	if s.Text != "" && !bytes.Contains(cmd, []byte(s.Text)) {
+		problems = append(problems, fmt.Sprintf("Excepted text % x as is, got % x", s.Text, cmd))
+	}
+	want := append([]byte(nil), cmd...)
+	// the command must not be shared: the change of it must not change the next one
+	for i := range cmd {
+		cmd[i] ^= 0xff
+	}
+	again := append([]byte(nil), s.Cmd()...)
+	for i := range cmd {
+		cmd[i] ^= 0xff
+	}
+	if !bytes.Equal(again, want) {
+		problems = append(problems, fmt.Sprintf("Excepted the same command % x, got % x", want, again))
+	}
+	if decoder != nil {
+		cmds, rest := decoder.Decode(want)
+		if len(cmds) != 1 || len(rest) != 0 || cmds[0].Name != s.Name || !sameArgs(cmds[0].Args, s.Args) {
+			expected := driver.Command{Name: s.Name, Args: s.Args}
+			problems = append(problems, fmt.Sprintf("Excepted % x decoded as %v, got %v (rest % x)", want, expected, cmds, rest))
+		}
+	}
+	return problems
+}
+
+func sameArgs(got, want []interface{}) bool {
+	if len(got) == 0 && len(want) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// checkBounds returns the problems of the commands of the rows out of the display
+func checkBounds(p driver.Protocol, caps Caps) []string {
+	rows, _ := size(p, caps)
+	if rows == 0 {
+		return nil
+	}
+	var problems []string
+	for _, row := range []byte{0, rows + 1} {
+		if cmd := p.PrintRowCmd(row, "Price:10$"); len(cmd) != 0 {
+			problems = append(problems, fmt.Sprintf("Excepted empty PrintRow of row %d, got % x", row, cmd))
+		}
+	}
+	return problems
+}
+
+// checkCapabilities returns the problems of the optional interfaces of the protocol
+func checkCapabilities(p driver.Protocol) []string {
+	var problems []string
+	if sizer, ok := p.(driver.Sizer); ok {
+		if rows, cols := sizer.Size(); rows == 0 || cols == 0 {
+			problems = append(problems, fmt.Sprintf("Excepted size, got %dx%d", rows, cols))
+		}
+	}
+	if paced, ok := p.(driver.Paced); ok {
+		timing := paced.Timing()
+		if timing.AfterInit < 0 || timing.AfterClear < 0 || timing.AfterBrightness < 0 || timing.BytesPerSecond < 0 {
+			problems = append(problems, fmt.Sprintf("Excepted non-negative timing, got %+v", timing))
+		}
+	}
+	if querier, ok := p.(driver.StatusQuerier); ok {
+		if cmd, size := querier.StatusQuery(); len(cmd) == 0 || size <= 0 {
+			problems = append(problems, fmt.Sprintf("Excepted status query, got % x of %d byte(s)", cmd, size))
+		}
+		if cmd, size := querier.IdentifyQuery(); len(cmd) != 0 && size <= 0 {
+			problems = append(problems, fmt.Sprintf("Excepted size of identify answer, got %d", size))
+		}
+	}
+	return problems
+}
+
+// golden compares the command with the golden file or writes it if update is set
+func golden(path string, cmd []byte, update bool) error {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, cmd, 0644)
+	}
+	want, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("No golden file %s, run the test with Caps.Update", path)
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(cmd, want) {
+		return fmt.Errorf("Excepted % x of %s, got % x", want, path, cmd)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package protocoltest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arteev/gold/firich"
+)
+
+type brokenProtocol struct {
+	firich.FirichProtocol
+	shared []byte
+}
+
+// InitCmd returns the shared slice
+func (p brokenProtocol) InitCmd() []byte {
+	return p.shared
+}
+
+// CursorMoveCmd swaps the row and the column
+func (p brokenProtocol) CursorMoveCmd(row, col byte) []byte {
+	return []byte{0x1b, 0x6c, row, col}
+}
+
+// PrintRowCmd ignores the row and writes the text as runes
+func (p brokenProtocol) PrintRowCmd(row byte, text string) []byte {
+	var b []byte
+	for _, r := range text {
+		b = append(b, byte(r))
+	}
+	return append([]byte{0x1b, 0x51, 0x40 + row}, append(b, 0x0d)...)
+}
+
+func TestBrokenProtocol(t *testing.T) {
+	p := brokenProtocol{shared: []byte{0x1b, 0x40}}
+	caps := Caps{Unsupported: []string{"CharSize", "Reverse", "Underline"}, Decoder: firich.Decoder{}}
+	var got []string
+	unsupported, problems := unsupportedSet(append(caps.Unsupported, "Fake"))
+	got = append(got, problems...)
+	unsupported["Reverse"] = false
+	for _, s := range samples(p, caps) {
+		for _, problem := range check(s, unsupported[s.Name], caps.Decoder) {
+			got = append(got, s.Label+": "+problem)
+		}
+	}
+	got = append(got, checkBounds(p, caps)...)
+	want := []string{
+		`Unknown unsupported command "Fake"`,
+		"Init: Excepted the same command 1b 40, got e4 bf",
+		"Reverse_true: Excepted command, got empty",
+		"PrintRow_1_encoded: Excepted text 91 e3 ac ac a0 3a ff as is, got 1b 51 41 fd 2c fd 3a fd 0d",
+		`PrintRow_1_encoded: Excepted 1b 51 41 fd 2c fd 3a fd 0d decoded as PrintRow(1, "\x91㬬\xa0:\xff"), got [PrintRow(1, "\xfd,\xfd:\xfd")] (rest )`,
+		"PrintRow_2_encoded: Excepted text 91 e3 ac ac a0 3a ff as is, got 1b 51 42 fd 2c fd 3a fd 0d",
+		`PrintRow_2_encoded: Excepted 1b 51 42 fd 2c fd 3a fd 0d decoded as PrintRow(2, "\x91㬬\xa0:\xff"), got [PrintRow(2, "\xfd,\xfd:\xfd")] (rest )`,
+		"CursorMove_2_20: Excepted 1b 6c 02 14 decoded as CursorMove(2, 20), got [CursorMove(20, 2)] (rest )",
+		"Excepted empty PrintRow of row 0, got 1b 51 40 50 72 69 63 65 3a 31 30 24 0d",
+		"Excepted empty PrintRow of row 3, got 1b 51 43 50 72 69 63 65 3a 31 30 24 0d",
+	}
+	if string(p.shared) != "\x1b\x40" {
+		t.Errorf("Excepted the shared command restored, got % x", p.shared)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Excepted\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,267 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// Header is the first line of the record.
+//
+// The record is the text, one entry per line:
+//
+//	# gold record 1
+//	# start 2026-10-19T10:00:00.123456789Z
+//	0.000000 > 1b 40 # Init
+//	0.104512 > 1b 51 41 50 72 69 63 65 0d # PrintRow(1, "Price")
+//	0.250000 < 06
+//
+// The first field is the time since the start of the record in seconds,
+// the second is the direction: ">" is written to the device, "<" is read
+// from it. The bytes are in hex. The decoded commands after "#" are
+// optional and are separated by "; ". The lines starting with "#" are the
+// comments, the "# start" comment is the time the record started.
+const Header = "# gold record 1"
+
+// Entry is the data written to or read from the device
+type Entry struct {
+	// Time is the time since the start of the record
+	Time      time.Duration
+	Direction driver.Direction
+	Data      []byte
+	// Comment is the decoded commands, if any
+	Comment string
+}
+
+func (e Entry) String() string {
+	dir := ">"
+	if e.Direction == driver.Read {
+		dir = "<"
+	}
+	line := fmt.Sprintf("%.6f %s % x", e.Time.Seconds(), dir, e.Data)
+	if e.Comment != "" {
+		line += " # " + e.Comment
+	}
+	return line
+}
+
+// Port is the port of the device, com.Serialer
+type Port interface {
+	Write(b []byte) (n int, err error)
+	Close() error
+	Read(b []byte) (n int, err error)
+}
+
+// Recorder is the port writing all the data passed through it to the record
+type Recorder struct {
+	port Port
+
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	now     func() time.Time
+	decoder driver.Decoder
+	rest    []byte
+	err     error
+}
+
+// New returns the recorder of the port writing the record to w
+func New(port Port, w io.Writer) *Recorder {
+	r := &Recorder{
+		port:  port,
+		w:     w,
+		start: time.Now(),
+		now:   time.Now,
+	}
+	_, r.err = fmt.Fprintf(w, "%s\n# start %s\n", Header, r.start.UTC().Format(time.RFC3339Nano))
+	return r
+}
+
+// SetDecoder sets the decoder of the protocol, the commands written
+// to the device are added to the record
+func (r *Recorder) SetDecoder(decoder driver.Decoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoder = decoder
+	r.rest = nil
+}
+
+// Err returns the first error of the write of the record
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) Write(b []byte) (n int, err error) {
+	n, err = r.port.Write(b)
+	if n > 0 {
+		r.add(driver.Write, b[:n])
+	}
+	return n, err
+}
+
+func (r *Recorder) Read(b []byte) (n int, err error) {
+	n, err = r.port.Read(b)
+	if n > 0 {
+		r.add(driver.Read, b[:n])
+	}
+	return n, err
+}
+
+// Close closes the port, the record is left open
+func (r *Recorder) Close() error {
+	return r.port.Close()
+}
+
+func (r *Recorder) add(dir driver.Direction, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := Entry{Time: r.now().Sub(r.start), Direction: dir, Data: data}
+	if dir == driver.Write && r.decoder != nil {
+		cmds, rest := r.decoder.Decode(append(r.rest, data...))
+		r.rest = append([]byte(nil), rest...)
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.String()
+		}
+		e.Comment = strings.Join(names, "; ")
+	}
+	if r.err != nil {
+		return
+	}
+	_, r.err = fmt.Fprintln(r.w, e)
+}
+
+// maxLine is the limit of the line of the record, the written data
+// take 3 characters per byte
+const maxLine = 16 << 20
+
+// Reader reads the entries of the record
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	// Start is the time the record started, zero if unknown
+	Start time.Time
+}
+
+// NewReader returns the reader of the record
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next entry of the record or io.EOF
+func (r *Reader) Next() (Entry, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			r.comment(line)
+			continue
+		}
+		e, err := parseEntry(line)
+		if err != nil {
+			return Entry{}, fmt.Errorf("record: line %d: %v", r.line, err)
+		}
+		return e, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Entry{}, err
+	}
+	return Entry{}, io.EOF
+}
+
+func (r *Reader) comment(line string) {
+	value := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	if strings.HasPrefix(value, "start ") {
+		if start, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(value, "start ")); err == nil {
+			r.Start = start
+		}
+	}
+}
+
+func parseEntry(line string) (Entry, error) {
+	var e Entry
+	if i := strings.Index(line, "#"); i >= 0 {
+		e.Comment = strings.TrimSpace(line[i+1:])
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return e, fmt.Errorf("want time and direction, got %q", line)
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || seconds < 0 {
+		return e, fmt.Errorf("invalid time %q", fields[0])
+	}
+	e.Time = time.Duration(seconds * float64(time.Second))
+	switch fields[1] {
+	case ">":
+		e.Direction = driver.Write
+	case "<":
+		e.Direction = driver.Read
+	default:
+		return e, fmt.Errorf("invalid direction %q", fields[1])
+	}
+	if e.Data, err = hex.DecodeString(strings.Join(fields[2:], "")); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// Replay writes the entries written to the device to w keeping the time
+// between them. The speed divides the time, e.g. 2 replays twice as fast,
+// zero writes without delays. The entries read from the device are skipped.
+func Replay(ctx context.Context, r *Reader, w io.Writer, speed float64) error {
+	start := time.Now()
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if e.Direction != driver.Write {
+			continue
+		}
+		if speed > 0 {
+			at := start.Add(time.Duration(float64(e.Time) / speed))
+			if err := sleep(ctx, time.Until(at)); err != nil {
+				return err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := w.Write(e.Data); err != nil {
+			return err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
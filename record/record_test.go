@@ -0,0 +1,106 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/emulator"
+	"github.com/arteev/gold/firich"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+	dsp := emulator.NewFirich()
+	dsp.Respond([]byte{0x06})
+	r := New(dsp, &buf)
+	r.SetDecoder(firich.Decoder{})
+	clock := r.start
+	r.now = func() time.Time {
+		clock = clock.Add(100 * time.Millisecond)
+		return clock
+	}
+	p := firich.FirichProtocol{}
+	r.Write(p.InitCmd())
+	r.Write(p.PrintRowCmd(1, "Price:10$"))
+	r.Read(make([]byte, 1))
+	r.Write([]byte{0x1b, 0x51})
+	r.Write([]byte("BTotal\r"))
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `0.100000 > 1b 40 # Init()
+0.200000 > 1b 51 41 50 72 69 63 65 3a 31 30 24 0d # PrintRow(1, "Price:10$")
+0.300000 < 06
+0.400000 > 1b 51
+0.500000 > 42 54 6f 74 61 6c 0d # PrintRow(2, "Total")
+`
+	lines := strings.SplitN(buf.String(), "\n", 3)
+	if lines[0] != Header || !strings.HasPrefix(lines[1], "# start ") {
+		t.Fatalf("Excepted header, got %q", lines[:2])
+	}
+	if lines[2] != want {
+		t.Errorf("Excepted\n%s\ngot\n%s", want, lines[2])
+	}
+
+	reader := NewReader(strings.NewReader(buf.String()))
+	e, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantEntry := (Entry{Time: 100 * time.Millisecond, Direction: driver.Write, Data: []byte{0x1b, 0x40}, Comment: "Init()"}); !reflect.DeepEqual(e, wantEntry) {
+		t.Errorf("Excepted %v, got %v", wantEntry, e)
+	}
+	if !reader.Start.Equal(r.start) {
+		t.Errorf("Excepted start %v, got %v", r.start, reader.Start)
+	}
+
+	replayed := emulator.NewFirich()
+	start := time.Now()
+	if err := Replay(context.Background(), NewReader(strings.NewReader(buf.String())), replayed, 10); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Excepted replay for 50ms, got %v", elapsed)
+	}
+	if got := replayed.Row(1) + "|" + replayed.Row(2); got != "Price:10$|Total" {
+		t.Errorf("Excepted replayed rows, got %q", got)
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	reader := NewReader(strings.NewReader(Header + "\n0.1 > 1b 40\n0.2 ? 1b\n"))
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); err == nil || err.Error() != `record: line 3: invalid direction "?"` {
+		t.Errorf("Excepted error of direction, got %v", err)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Excepted EOF, got %v", err)
+	}
+}
+
+func TestReaderLongLine(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(emulator.NewFirich(), &buf)
+	data := bytes.Repeat([]byte("A"), 30000)
+	if _, err := r.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewReader(&buf)
+	e, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(e.Data, data) {
+		t.Errorf("Excepted %d bytes, got %d", len(data), len(e.Data))
+	}
+}
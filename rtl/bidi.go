@@ -0,0 +1,297 @@
+package rtl
+
+import (
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Direction of a paragraph
+type Direction int
+
+// Directions of the paragraph
+const (
+	// Auto takes the direction of the first strong character, left to right if there is none
+	Auto Direction = iota
+	LeftToRight
+	RightToLeft
+)
+
+// mirrors are the characters replaced in the right to left runs
+var mirrors = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+	'‹': '›', '›': '‹',
+}
+
+// Reorder returns the line of the text in the visual order (left to right
+// as it must be sent to the display) by the Unicode Bidirectional Algorithm.
+// The explicit embeddings and isolates are not supported and removed.
+func Reorder(text string, dir Direction) string {
+	runes := []rune(text)
+	classes := make([]bidi.Class, 0, len(runes))
+	kept := runes[:0:0]
+	for _, r := range runes {
+		props, _ := bidi.LookupRune(r)
+		class := props.Class()
+		if isExplicit(class) {
+			continue
+		}
+		kept = append(kept, r)
+		classes = append(classes, class)
+	}
+	runes = kept
+	if len(runes) == 0 {
+		return ""
+	}
+
+	base := 0
+	switch dir {
+	case RightToLeft:
+		base = 1
+	case Auto:
+		base = firstStrong(classes)
+	}
+
+	resolveWeak(classes, base)
+	resolveNeutral(classes, base)
+	levels := resolveImplicit(classes, base)
+
+	// L1: trailing whitespace takes the paragraph level
+	for i := len(runes) - 1; i >= 0; i-- {
+		props, _ := bidi.LookupRune(runes[i])
+		if c := props.Class(); c != bidi.WS && c != bidi.S && c != bidi.B && c != bidi.BN {
+			break
+		}
+		levels[i] = base
+	}
+
+	// L4: mirrored characters
+	for i, r := range runes {
+		if levels[i]%2 == 1 {
+			if m, ok := mirrors[r]; ok {
+				runes[i] = m
+			}
+		}
+	}
+
+	// L2: reverse the sequences from the highest level to the lowest odd level
+	highest, lowestOdd := 0, 0xff
+	for _, l := range levels {
+		if l > highest {
+			highest = l
+		}
+		if l%2 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(levels); {
+			if levels[i] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(levels) && levels[j] >= level {
+				j++
+			}
+			reverse(runes[i:j])
+			reverseLevels(levels[i:j])
+			i = j
+		}
+	}
+	return string(runes)
+}
+
+func isExplicit(c bidi.Class) bool {
+	switch c {
+	case bidi.LRO, bidi.RLO, bidi.LRE, bidi.RLE, bidi.PDF,
+		bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+		return true
+	}
+	return false
+}
+
+func firstStrong(classes []bidi.Class) int {
+	for _, c := range classes {
+		switch c {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		}
+	}
+	return 0
+}
+
+func strongOf(level int) bidi.Class {
+	if level%2 == 1 {
+		return bidi.R
+	}
+	return bidi.L
+}
+
+// resolveWeak applies the rules W1-W7
+func resolveWeak(classes []bidi.Class, base int) {
+	sos := strongOf(base)
+
+	// W1
+	prev := sos
+	for i, c := range classes {
+		if c == bidi.NSM {
+			classes[i] = prev
+		}
+		prev = classes[i]
+	}
+
+	// W2, W3
+	last := sos
+	for i, c := range classes {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			last = c
+		case bidi.EN:
+			if last == bidi.AL {
+				classes[i] = bidi.AN
+			}
+		}
+		if classes[i] == bidi.AL {
+			classes[i] = bidi.R
+		}
+	}
+
+	// W4
+	for i := 1; i+1 < len(classes); i++ {
+		prev, next := classes[i-1], classes[i+1]
+		switch classes[i] {
+		case bidi.ES:
+			if prev == bidi.EN && next == bidi.EN {
+				classes[i] = bidi.EN
+			}
+		case bidi.CS:
+			if prev == next && (prev == bidi.EN || prev == bidi.AN) {
+				classes[i] = prev
+			}
+		}
+	}
+
+	// W5
+	for i := 0; i < len(classes); {
+		if classes[i] != bidi.ET {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && classes[j] == bidi.ET {
+			j++
+		}
+		if (i > 0 && classes[i-1] == bidi.EN) || (j < len(classes) && classes[j] == bidi.EN) {
+			for k := i; k < j; k++ {
+				classes[k] = bidi.EN
+			}
+		}
+		i = j
+	}
+
+	// W6
+	for i, c := range classes {
+		if c == bidi.ES || c == bidi.ET || c == bidi.CS {
+			classes[i] = bidi.ON
+		}
+	}
+
+	// W7
+	last = sos
+	for i, c := range classes {
+		switch c {
+		case bidi.L, bidi.R:
+			last = c
+		case bidi.EN:
+			if last == bidi.L {
+				classes[i] = bidi.L
+			}
+		}
+	}
+}
+
+func isNeutral(c bidi.Class) bool {
+	switch c {
+	case bidi.ON, bidi.WS, bidi.S, bidi.B, bidi.BN:
+		return true
+	}
+	return false
+}
+
+// direction of a strong or number class for the rules N1, N2
+func neutralDirection(c bidi.Class) bidi.Class {
+	if c == bidi.EN || c == bidi.AN {
+		return bidi.R
+	}
+	return c
+}
+
+// resolveNeutral applies the rules N1, N2
+func resolveNeutral(classes []bidi.Class, base int) {
+	e := strongOf(base)
+	for i := 0; i < len(classes); {
+		if !isNeutral(classes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && isNeutral(classes[j]) {
+			j++
+		}
+		before, after := e, e
+		if i > 0 {
+			before = neutralDirection(classes[i-1])
+		}
+		if j < len(classes) {
+			after = neutralDirection(classes[j])
+		}
+		dir := e
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			classes[k] = dir
+		}
+		i = j
+	}
+}
+
+// resolveImplicit applies the rules I1, I2
+func resolveImplicit(classes []bidi.Class, base int) []int {
+	levels := make([]int, len(classes))
+	for i, c := range classes {
+		level := base
+		if base%2 == 0 {
+			switch c {
+			case bidi.R:
+				level++
+			case bidi.AN, bidi.EN:
+				level += 2
+			}
+		} else {
+			switch c {
+			case bidi.L, bidi.EN, bidi.AN:
+				level++
+			}
+		}
+		levels[i] = level
+	}
+	return levels
+}
+
+func reverse(runes []rune) {
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+}
+
+func reverseLevels(levels []int) {
+	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+		levels[i], levels[j] = levels[j], levels[i]
+	}
+}
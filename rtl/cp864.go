@@ -0,0 +1,150 @@
+package rtl
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// CodePage864 is the IBM Code Page 864 (Arabic) encoding. The encoder accepts
+// the Arabic letters and all their presentation forms: a form missing
+// in the code page is written with the nearest one (e.g. medial as initial).
+var CodePage864 encoding.Encoding = &codePage864{}
+
+var errUnsupported = errors.New("rtl: rune not supported by CP864")
+
+// cp864 are the runes of the bytes 0x80-0xff, zero is not defined
+var cp864 = [128]rune{
+	0x00b0, 0x00b7, 0x2219, 0x221a, 0x2592, 0x2500, 0x2502, 0x253c,
+	0x2524, 0x252c, 0x251c, 0x2534, 0x2510, 0x250c, 0x2514, 0x2518,
+	0x03b2, 0x221e, 0x03c6, 0x00b1, 0x00bd, 0x00bc, 0x2248, 0x00ab,
+	0x00bb, 0xfef7, 0xfef8, 0, 0, 0xfefb, 0xfefc, 0,
+	0x00a0, 0x00ad, 0xfe82, 0x00a3, 0x00a4, 0xfe84, 0, 0,
+	0xfe8e, 0xfe8f, 0xfe95, 0xfe99, 0x060c, 0xfe9d, 0xfea1, 0xfea5,
+	0x0660, 0x0661, 0x0662, 0x0663, 0x0664, 0x0665, 0x0666, 0x0667,
+	0x0668, 0x0669, 0xfed1, 0x061b, 0xfeb1, 0xfeb5, 0xfeb9, 0x061f,
+	0x00a2, 0xfe80, 0xfe81, 0xfe83, 0xfe85, 0xfeca, 0xfe8b, 0xfe8d,
+	0xfe91, 0xfe93, 0xfe97, 0xfe9b, 0xfe9f, 0xfea3, 0xfea7, 0xfea9,
+	0xfeab, 0xfead, 0xfeaf, 0xfeb3, 0xfeb7, 0xfebb, 0xfebf, 0xfec1,
+	0xfec5, 0xfecb, 0xfecf, 0x00a6, 0x00ac, 0x00f7, 0x00d7, 0xfec9,
+	0x0640, 0xfed3, 0xfed7, 0xfedb, 0xfedf, 0xfee3, 0xfee7, 0xfeeb,
+	0xfeed, 0xfeef, 0xfef3, 0xfebd, 0xfecc, 0xfece, 0xfecd, 0xfee1,
+	0xfe7d, 0x0651, 0xfee5, 0xfee9, 0xfeec, 0xfef0, 0xfef2, 0xfed0,
+	0xfed5, 0xfef5, 0xfef6, 0xfedd, 0xfed9, 0xfef1, 0x25a0, 0,
+}
+
+// cp864Encode maps the runes to the bytes including the fallbacks of the missing forms
+var cp864Encode = make(map[rune]byte)
+
+func init() {
+	for i, r := range cp864 {
+		if r != 0 {
+			cp864Encode[r] = byte(0x80 + i)
+		}
+	}
+	// the Arabic percent sign takes the place of '%'
+	cp864Encode[0x066a] = '%'
+
+	fallback := func(form rune, alternatives ...rune) {
+		if form == 0 {
+			return
+		}
+		if _, ok := cp864Encode[form]; ok {
+			return
+		}
+		for _, alt := range alternatives {
+			if b, ok := cp864Encode[alt]; ok && alt != 0 {
+				cp864Encode[form] = b
+				return
+			}
+		}
+	}
+	for letter, f := range arabic {
+		fallback(f.isolated, f.final, f.initial)
+		fallback(f.initial, f.isolated)
+		fallback(f.medial, f.initial, f.final, f.isolated)
+		fallback(f.final, f.isolated)
+		fallback(letter, f.isolated, f.final)
+	}
+	for _, lig := range lamAlef {
+		fallback(lig+1, lig)
+	}
+}
+
+type codePage864 struct{}
+
+func (c *codePage864) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: cp864Decoder{}}
+}
+
+func (c *codePage864) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: cp864Encoder{}}
+}
+
+func (c *codePage864) String() string {
+	return "IBM Code Page 864"
+}
+
+type cp864Decoder struct{ transform.NopResetter }
+
+func (cp864Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		b := src[nSrc]
+		r := rune(b)
+		switch {
+		case b == '%':
+			r = 0x066a
+		case b >= 0x80:
+			r = cp864[b-0x80]
+			if r == 0 {
+				r = utf8.RuneError
+			}
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+type cp864Encoder struct{ transform.NopResetter }
+
+func (cp864Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			return nDst, nSrc, errUnsupported
+		}
+		b, ok := encode864(r)
+		if !ok {
+			return nDst, nSrc, errUnsupported
+		}
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+func encode864(r rune) (byte, bool) {
+	if r < 0x80 {
+		return byte(r), true
+	}
+	b, ok := cp864Encode[r]
+	return b, ok
+}
+
+// HasPresentationForms reports whether the encoding writes the Arabic letters
+// with the presentation forms, so the text must be shaped before the encoding.
+func HasPresentationForms(enc encoding.Encoding) bool {
+	return enc == CodePage864
+}
@@ -0,0 +1,70 @@
+package rtl
+
+import (
+	"testing"
+)
+
+func TestReorder(t *testing.T) {
+	cases := []struct {
+		Text string
+		Dir  Direction
+		Want string
+	}{
+		{"Price:10$", Auto, "Price:10$"},
+		{"שלום 123", Auto, "123 םולש"},
+		{"Total: שלום", Auto, "Total: םולש"},
+		{"Total: שלום", RightToLeft, "םולש :Total"},
+		{"מחיר (10.50)", Auto, "(10.50) ריחמ"},
+		{"", Auto, ""},
+		{"abc ‫def", Auto, "abc def"},
+	}
+	for _, c := range cases {
+		if got := Reorder(c.Text, c.Dir); got != c.Want {
+			t.Errorf("Reorder(%q) excepted %q, got %q", c.Text, c.Want, got)
+		}
+	}
+}
+
+func TestShape(t *testing.T) {
+	cases := []struct {
+		Text string
+		Want string
+	}{
+		{"سلام", "ﺳﻼﻡ"},
+		{"بيت", "ﺑﻴﺖ"},
+		{"دار", "\ufea9\ufe8d\ufead"},
+		{"Total 5", "Total 5"},
+	}
+	for _, c := range cases {
+		if got := Shape(c.Text); got != c.Want {
+			t.Errorf("Shape(%q) excepted %+q, got %+q", c.Text, c.Want, got)
+		}
+	}
+}
+
+func TestCodePage864(t *testing.T) {
+	enc := CodePage864.NewEncoder()
+	got, err := enc.String(Shape("سلام 50%"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\xd3\x9e\xef 50%"; got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+
+	// medial beh is written as initial
+	if got, err := enc.String("ﺒ"); err != nil || got != "\xc8" {
+		t.Errorf("Excepted %q, got %q,%v", "\xc8", got, err)
+	}
+	if _, err := enc.String("€"); err == nil {
+		t.Error("Excepted error of unsupported rune")
+	}
+
+	dec := CodePage864.NewDecoder()
+	if got, err := dec.String("\xc7\xb1 a"); err != nil || got != "ﺍ١ a" {
+		t.Errorf("Excepted %+q, got %+q,%v", "ﺍ١ a", got, err)
+	}
+	if !HasPresentationForms(CodePage864) {
+		t.Error("Excepted CP864 has presentation forms")
+	}
+}
@@ -0,0 +1,151 @@
+package rtl
+
+// joining types of the Arabic letters
+const (
+	nonJoining = iota
+	rightJoining
+	dualJoining
+	joinCausing
+)
+
+// forms are the presentation forms of an Arabic letter
+type forms struct {
+	join     int
+	isolated rune
+	final    rune
+	initial  rune
+	medial   rune
+}
+
+func right(isolated rune) forms {
+	return forms{rightJoining, isolated, isolated + 1, 0, 0}
+}
+
+func dual(isolated rune) forms {
+	return forms{dualJoining, isolated, isolated + 1, isolated + 2, isolated + 3}
+}
+
+// arabic are the presentation forms B of the letters
+var arabic = map[rune]forms{
+	'ء': {nonJoining, 0xfe80, 0, 0, 0},
+	'آ': right(0xfe81),
+	'أ': right(0xfe83),
+	'ؤ': right(0xfe85),
+	'إ': right(0xfe87),
+	'ئ': dual(0xfe89),
+	'ا': right(0xfe8d),
+	'ب': dual(0xfe8f),
+	'ة': right(0xfe93),
+	'ت': dual(0xfe95),
+	'ث': dual(0xfe99),
+	'ج': dual(0xfe9d),
+	'ح': dual(0xfea1),
+	'خ': dual(0xfea5),
+	'د': right(0xfea9),
+	'ذ': right(0xfeab),
+	'ر': right(0xfead),
+	'ز': right(0xfeaf),
+	'س': dual(0xfeb1),
+	'ش': dual(0xfeb5),
+	'ص': dual(0xfeb9),
+	'ض': dual(0xfebd),
+	'ط': dual(0xfec1),
+	'ظ': dual(0xfec5),
+	'ع': dual(0xfec9),
+	'غ': dual(0xfecd),
+	'ـ': {joinCausing, 0x0640, 0x0640, 0x0640, 0x0640},
+	'ف': dual(0xfed1),
+	'ق': dual(0xfed5),
+	'ك': dual(0xfed9),
+	'ل': dual(0xfedd),
+	'م': dual(0xfee1),
+	'ن': dual(0xfee5),
+	'ه': dual(0xfee9),
+	'و': right(0xfeed),
+	'ى': right(0xfeef),
+	'ي': dual(0xfef1),
+}
+
+// lamAlef are the isolated ligatures of lam with the alef, the final form follows the isolated one
+var lamAlef = map[rune]rune{
+	'آ': 0xfef5,
+	'أ': 0xfef7,
+	'إ': 0xfef9,
+	'ا': 0xfefb,
+}
+
+const lam = 'ل'
+
+// isTransparent reports whether the rune is an Arabic mark skipped by the joining
+func isTransparent(r rune) bool {
+	return (r >= 0x064b && r <= 0x065f) || r == 0x0670
+}
+
+// Shape replaces the Arabic letters of the text in the logical order with
+// the presentation forms by the joining with the neighbour letters.
+func Shape(text string) string {
+	runes := []rune(text)
+	result := make([]rune, 0, len(runes))
+
+	// neighbour returns the index of the next not transparent rune in the direction
+	neighbour := func(i, step int) int {
+		for i += step; i >= 0 && i < len(runes); i += step {
+			if !isTransparent(runes[i]) {
+				return i
+			}
+		}
+		return -1
+	}
+	joinsNext := func(i int) bool {
+		if i < 0 {
+			return false
+		}
+		f, ok := arabic[runes[i]]
+		return ok && (f.join == dualJoining || f.join == joinCausing)
+	}
+	joinsPrev := func(i int) bool {
+		if i < 0 {
+			return false
+		}
+		f, ok := arabic[runes[i]]
+		return ok && f.join != nonJoining
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		f, ok := arabic[r]
+		if !ok {
+			result = append(result, r)
+			continue
+		}
+		prev := joinsNext(neighbour(i, -1))
+		next := neighbour(i, 1)
+
+		if r == lam && next >= 0 {
+			if lig, ok := lamAlef[runes[next]]; ok {
+				if prev {
+					lig++
+				}
+				result = append(result, lig)
+				// the marks between lam and alef follow the ligature
+				result = append(result, runes[i+1:next]...)
+				i = next
+				continue
+			}
+		}
+
+		canNext := (f.join == dualJoining || f.join == joinCausing) && joinsPrev(next)
+		canPrev := prev && f.join != nonJoining
+		switch {
+		case canPrev && canNext:
+			result = append(result, f.medial)
+		case canPrev:
+			result = append(result, f.final)
+		case canNext:
+			result = append(result, f.initial)
+		default:
+			result = append(result, f.isolated)
+		}
+	}
+	return string(result)
+}
@@ -0,0 +1,281 @@
+package screens
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/format"
+)
+
+// Align of the text in a row
+type Align int
+
+// Alignments
+const (
+	Left Align = iota
+	Right
+	Center
+)
+
+var alignNames = map[string]Align{
+	"left":   Left,
+	"right":  Right,
+	"center": Center,
+}
+
+// UnmarshalText parses the align name: left, right or center
+func (a *Align) UnmarshalText(text []byte) error {
+	align, ok := alignNames[strings.ToLower(string(text))]
+	if !ok {
+		return fmt.Errorf("screens: unknown align %q", text)
+	}
+	*a = align
+	return nil
+}
+
+// Row is the layout of a display row
+type Row struct {
+	Template string `json:"template"`
+	Align    Align  `json:"align"`
+}
+
+type screen struct {
+	rows  []Row
+	tmpls []*template.Template
+}
+
+// Screens is a set of named layouts shown on a display
+type Screens struct {
+	mu      sync.RWMutex
+	dsp     driver.Display
+	rows    int
+	cols    int
+	funcs   template.FuncMap
+	locale  format.Locale
+	screens map[string]*screen
+}
+
+// New returns an empty set of screens for the display with the rows x cols geometry
+func New(dsp driver.Display, rows, cols int) *Screens {
+	s := &Screens{
+		dsp:     dsp,
+		rows:    rows,
+		cols:    cols,
+		locale:  format.Default,
+		screens: make(map[string]*screen),
+	}
+	s.funcs = template.FuncMap{
+		"money": s.money,
+		"qty":   s.qty,
+		"line":  s.line,
+	}
+	return s
+}
+
+// SetLocale sets the locale of the money, qty and line template functions
+func (s *Screens) SetLocale(l format.Locale) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locale = l
+}
+
+func (s *Screens) getLocale() format.Locale {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.locale
+}
+
+// Funcs adds the functions to the template function map.
+// It must be called before the screens are added.
+func (s *Screens) Funcs(funcs template.FuncMap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, fn := range funcs {
+		s.funcs[name] = fn
+	}
+}
+
+// Add parses the row templates and adds the screen. A screen with the same name is replaced.
+func (s *Screens) Add(name string, rows ...Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(rows) > s.rows {
+		return fmt.Errorf("screens: screen %q has %d rows, display has %d", name, len(rows), s.rows)
+	}
+	scr := &screen{rows: rows}
+	for i, row := range rows {
+		tmpl, err := template.New(fmt.Sprintf("%s:%d", name, i+1)).Funcs(s.funcs).Parse(row.Template)
+		if err != nil {
+			return err
+		}
+		scr.tmpls = append(scr.tmpls, tmpl)
+	}
+	s.screens[name] = scr
+	return nil
+}
+
+// Load adds the screens from JSON of the form:
+//
+//	{"subtotal": [{"template": "{{.Item}}"}, {"template": "{{money .Total}}", "align": "right"}]}
+func (s *Screens) Load(r io.Reader) error {
+	var config map[string][]Row
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.Add(name, config[name]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile adds the screens from the JSON file
+func (s *Screens) LoadFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.Load(f)
+}
+
+// Names returns a sorted list of the names of the screens
+func (s *Screens) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []string
+	for name := range s.screens {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Render executes the templates of the screen and returns the aligned rows
+func (s *Screens) Render(name string, data interface{}) ([]string, error) {
+	s.mu.RLock()
+	scr, ok := s.screens[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("screens: unknown screen %q", name)
+	}
+	result := make([]string, len(scr.tmpls))
+	for i, tmpl := range scr.tmpls {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		result[i] = s.align(buf.String(), scr.rows[i].Align)
+	}
+	return result, nil
+}
+
+// Show renders the screen and prints it on the display
+func (s *Screens) Show(name string, data interface{}) error {
+	rows, err := s.Render(name, data)
+	if err != nil {
+		return err
+	}
+	// the rows below the screen are blanked
+	for len(rows) < s.rows {
+		rows = append(rows, s.align("", Left))
+	}
+	for i, text := range rows {
+		if err := s.dsp.PrintRow(byte(i+1), text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// align pads the text with spaces to the width of the display or truncates it.
+// The text is measured in the cells of the display by driver.TextWidth.
+func (s *Screens) align(text string, a Align) string {
+	n := driver.TextWidth(s.dsp, text)
+	for n > s.cols {
+		_, size := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-size]
+		n = driver.TextWidth(s.dsp, text)
+	}
+	pad := s.cols - n
+	switch a {
+	case Right:
+		return strings.Repeat(" ", pad) + text
+	case Center:
+		return strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+	}
+	return text + strings.Repeat(" ", pad)
+}
+
+// money formats the amount in the major units: {{money .Total}}
+func (s *Screens) money(v interface{}) (string, error) {
+	l := s.getLocale()
+	minor, err := minorUnits(v, l.Decimals)
+	if err != nil {
+		return "", err
+	}
+	return l.Amount(minor), nil
+}
+
+// qty formats the quantity: {{qty .Quantity}}
+func (s *Screens) qty(v interface{}) (string, error) {
+	q, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	return s.getLocale().Quantity(q), nil
+}
+
+// line formats the quantity times the price and the total in the width of the display:
+// {{line .Quantity .Price}}
+func (s *Screens) line(q, price interface{}) (string, error) {
+	l := s.getLocale()
+	qty, err := toFloat(q)
+	if err != nil {
+		return "", err
+	}
+	minor, err := minorUnits(price, l.Decimals)
+	if err != nil {
+		return "", err
+	}
+	return l.Line(qty, minor, s.cols), nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("screens: number of %T", v)
+}
+
+// minorUnits converts the amount in the major units to the minor ones
+func minorUnits(v interface{}, decimals int) (int64, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, fmt.Errorf("screens: money of %T", v)
+	}
+	return int64(math.Round(f * math.Pow10(decimals))), nil
+}
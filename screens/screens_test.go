@@ -0,0 +1,130 @@
+package screens
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/format"
+)
+
+type mockDisplay struct {
+	driver.Display
+	rows map[byte]string
+}
+
+func (m *mockDisplay) PrintRow(row byte, text string) error {
+	m.rows[row] = text
+	return nil
+}
+
+func TestShow(t *testing.T) {
+	dsp := &mockDisplay{rows: make(map[byte]string)}
+	s := New(dsp, 2, 20)
+	err := s.Add("subtotal",
+		Row{Template: "{{.Item}}"},
+		Row{Template: "Total:{{money .Total}}", Align: Right},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := struct {
+		Item  string
+		Total float64
+	}{"Молоко 3.2% 1л длинное название", 123.5}
+	if err := s.Show("subtotal", data); err != nil {
+		t.Fatal(err)
+	}
+	want := map[byte]string{
+		1: "Молоко 3.2% 1л длинн",
+		2: "        Total:123.50",
+	}
+	if !reflect.DeepEqual(dsp.rows, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.rows)
+	}
+
+	if err := s.Show("fake", nil); err == nil || err.Error() != `screens: unknown screen "fake"` {
+		t.Errorf("Excepted unknown screen error, got %v", err)
+	}
+	if err := s.Add("big", Row{}, Row{}, Row{}); err == nil {
+		t.Error("Excepted error for 3 rows")
+	}
+}
+
+// measuringDisplay writes № as "No" like the transliteration
+type measuringDisplay struct {
+	mockDisplay
+}
+
+func (m *measuringDisplay) TextWidth(text string) int {
+	return utf8.RuneCountInString(strings.Replace(text, "№", "No", -1))
+}
+
+func TestShowBlanksAndMeasures(t *testing.T) {
+	dsp := &measuringDisplay{mockDisplay{rows: make(map[byte]string)}}
+	s := New(dsp, 2, 10)
+	s.Add("two", Row{Template: "Receipt"}, Row{Template: "Total"})
+	s.Add("one", Row{Template: "№12345678", Align: Right})
+	if err := s.Show("two", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Show("one", nil); err != nil {
+		t.Fatal(err)
+	}
+	want := map[byte]string{
+		1: "№12345678",
+		2: "          ",
+	}
+	if !reflect.DeepEqual(dsp.rows, want) {
+		t.Errorf("Excepted %q, got %q", want, dsp.rows)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	s := New(nil, 2, 20)
+	s.SetLocale(format.RU)
+	err := s.Add("item",
+		Row{Template: "{{qty .Qty}} кг", Align: Right},
+		Row{Template: "{{line .Qty .Price}}"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"Qty": 1.5, "Price": 99.9}
+	got, err := s.Render("item", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"              1,5 кг", "1,5 x 99,90   149,85"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	config := `{
+		"welcome": [{"template": "WELCOME", "align": "center"}, {"template": "{{.}}", "align": "right"}],
+		"idle": [{"template": "{{.}}"}]
+	}`
+	s := New(nil, 2, 10)
+	if err := s.Load(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Names(); !reflect.DeepEqual(got, []string{"idle", "welcome"}) {
+		t.Errorf("Excepted names [idle welcome], got %q", got)
+	}
+	got, err := s.Render("welcome", "Shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{" WELCOME  ", "      Shop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+
+	if err := s.Load(strings.NewReader(`{"x": [{"align": "top"}]}`)); err == nil {
+		t.Error("Excepted error of unknown align")
+	}
+}
@@ -0,0 +1,61 @@
+package com
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// ASCII is the 7-bit ASCII encoding. Unlike no encoding, which writes the text
+// as is, the runes missing in ASCII are handled by the policy set by SetUnencodable.
+var ASCII encoding.Encoding = asciiEncoding{}
+
+var errNotASCII = errors.New("com: rune not supported by ASCII")
+
+type asciiEncoding struct{}
+
+func (asciiEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: asciiDecoder{}}
+}
+
+func (asciiEncoding) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: asciiEncoder{}}
+}
+
+func (asciiEncoding) String() string {
+	return "ASCII"
+}
+
+type asciiDecoder struct{ transform.NopResetter }
+
+func (asciiDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		r := rune(src[nSrc])
+		if r >= utf8.RuneSelf {
+			r = utf8.RuneError
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+type asciiEncoder struct{ transform.NopResetter }
+
+func (asciiEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		if src[nSrc] >= utf8.RuneSelf {
+			return nDst, nSrc, errNotASCII
+		}
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = src[nSrc]
+		nDst++
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,214 @@
+package com
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// wholeDisplay is the key of the blinker emulating the blink of all rows
+const wholeDisplay byte = 0
+
+// printedRow is the row printed by PrintRow
+type printedRow struct {
+	encoded string
+	// width is the number of the cells of the encoded text
+	width int
+}
+
+// blinker alternates the printed text of rows with blanks
+type blinker struct {
+	rows []byte
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Blink blinks the whole display. If the protocol has no blink command,
+// the blink is emulated by alternating the rows printed by PrintRow with blanks.
+// Zero interval stops blinking.
+func (s *Serial) Blink(interval time.Duration) error {
+	return s.BlinkContext(context.Background(), interval)
+}
+func (s *Serial) BlinkContext(ctx context.Context, interval time.Duration) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	fn := func() []byte {
+		return s.proto.BlinkCmd(interval)
+	}
+	err := s.sendFromProtocol(ctx, "Blink", fn, interval)
+	var rows []byte
+	for row := range s.rows {
+		rows = append(rows, row)
+	}
+	s.mu.Unlock()
+
+	if errors.Is(err, driver.ErrNotSupported) {
+		sort.Slice(rows, func(i, j int) bool { return rows[i] < rows[j] })
+		return s.startBlink(wholeDisplay, rows, interval)
+	}
+	if err == nil && interval <= 0 {
+		s.stopBlink(wholeDisplay)
+	}
+	return err
+}
+
+// BlinkRow blinks the text printed in the row by PrintRow.
+// Zero interval stops blinking.
+func (s *Serial) BlinkRow(row byte, interval time.Duration) error {
+	return s.BlinkRowContext(context.Background(), row, interval)
+}
+func (s *Serial) BlinkRowContext(ctx context.Context, row byte, interval time.Duration) error {
+	if row == wholeDisplay {
+		return s.BlinkContext(ctx, interval)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.checkRow("BlinkRow", row); err != nil {
+		return err
+	}
+	return s.startBlink(row, []byte{row}, interval)
+}
+
+func (s *Serial) Reverse(enabled bool) error {
+	return s.ReverseContext(context.Background(), enabled)
+}
+func (s *Serial) ReverseContext(ctx context.Context, enabled bool) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.ReverseCmd(enabled)
+	}
+	return s.sendFromProtocol(ctx, "Reverse", fn, enabled)
+}
+
+func (s *Serial) Underline(enabled bool) error {
+	return s.UnderlineContext(context.Background(), enabled)
+}
+func (s *Serial) UnderlineContext(ctx context.Context, enabled bool) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.UnderlineCmd(enabled)
+	}
+	return s.sendFromProtocol(ctx, "Underline", fn, enabled)
+}
+
+func (s *Serial) startBlink(key byte, rows []byte, interval time.Duration) error {
+	s.stopBlink(key)
+	if interval <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	err := s.check()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	b := &blinker{
+		rows: rows,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	s.muBlink.Lock()
+	if s.blinkers == nil {
+		s.blinkers = make(map[byte]*blinker)
+	}
+	s.blinkers[key] = b
+	s.muBlink.Unlock()
+
+	go s.blink(b, interval)
+	return nil
+}
+
+func (s *Serial) blink(b *blinker, interval time.Duration) {
+	defer close(b.done)
+	ticks, stop := s.newTicker(interval)
+	defer stop()
+	visible := true
+	for {
+		select {
+		case <-b.stop:
+			if !visible {
+				s.showBlinkRows(b.rows, true)
+			}
+			return
+		case <-ticks:
+			visible = !visible
+			s.showBlinkRows(b.rows, visible)
+		}
+	}
+}
+
+func (s *Serial) newTicker(interval time.Duration) (<-chan time.Time, func()) {
+	if s.ticker != nil {
+		return s.ticker(interval)
+	}
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+func (s *Serial) showBlinkRows(rows []byte, visible bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		printed, ok := s.rows[row]
+		if !ok {
+			continue
+		}
+		text := printed.encoded
+		if !visible {
+			text = strings.Repeat(" ", s.blankWidth(printed))
+		}
+		fn := func() []byte {
+			return s.proto.PrintRowCmd(row, text)
+		}
+		if s.sendFromProtocol(context.Background(), "PrintRow", fn, row, text) != nil {
+			return
+		}
+	}
+}
+
+// blankWidth returns the number of the columns blanking the row:
+// the width of the display if the protocol knows it or the width of the text
+func (s *Serial) blankWidth(printed printedRow) int {
+	if sizer, ok := s.proto.(driver.Sizer); ok {
+		if _, cols := sizer.Size(); cols > 0 {
+			return int(cols)
+		}
+	}
+	return printed.width
+}
+
+func (s *Serial) stopBlink(key byte) {
+	s.muBlink.Lock()
+	b, ok := s.blinkers[key]
+	delete(s.blinkers, key)
+	s.muBlink.Unlock()
+	if ok {
+		close(b.stop)
+		<-b.done
+	}
+}
+
+func (s *Serial) stopBlinkers() {
+	s.muBlink.Lock()
+	keys := make([]byte, 0, len(s.blinkers))
+	for key := range s.blinkers {
+		keys = append(keys, key)
+	}
+	s.muBlink.Unlock()
+	for _, key := range keys {
+		s.stopBlink(key)
+	}
+}
@@ -0,0 +1,100 @@
+package com
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// manualTicker returns the ticker of the serial sending the ticks of the channel
+func manualTicker(ticks chan time.Time) func(time.Duration) (<-chan time.Time, func()) {
+	return func(time.Duration) (<-chan time.Time, func()) {
+		return ticks, func() {}
+	}
+}
+
+func TestSoftwareBlink(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Sized    bool
+		Encoding encoding.Encoding
+		Blank    string
+	}{
+		// the encoded text is longer than the row
+		{"text", false, japanese.ShiftJIS, "   "},
+		// the transliterated text is wider than the text
+		{"transliterated", false, ASCII, "    "},
+		{"sized", true, japanese.ShiftJIS, strings.Repeat(" ", 20)},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			mprot := &mockProtocol{}
+			mser := &mockSerialer{}
+			var proto driver.Protocol = mprot
+			if c.Sized {
+				proto = mockSizedProtocol{mprot}
+			}
+			s := &Serial{proto: proto}
+			s.CreatePort(mser)
+			s.SetEncoding(c.Encoding)
+			s.SetUnencodable(Transliterate, "")
+			ticks := make(chan time.Time)
+			s.ticker = manualTicker(ticks)
+
+			var mu sync.Mutex
+			var printed []string
+			mprot.PrintRowCmdFn = func(row byte, text string) []byte {
+				mu.Lock()
+				defer mu.Unlock()
+				printed = append(printed, text)
+				return []byte(text)
+			}
+			mprot.BlinkCmdFn = func(time.Duration) []byte {
+				return nil
+			}
+			mser.WriteFn = func(b []byte) (int, error) {
+				return len(b), nil
+			}
+
+			if err := s.PrintRow(1, "ЖДИ"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Blink(time.Second); err != nil {
+				t.Fatalf("Excepted software blink, got %v", err)
+			}
+			// the second tick is received after the first one is handled
+			ticks <- time.Time{}
+			ticks <- time.Time{}
+			ticks <- time.Time{}
+			if err := s.Blink(0); err != nil && !errors.Is(err, driver.ErrNotSupported) {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			text := printed[0]
+			want := []string{text, c.Blank, text, c.Blank, text}
+			if !reflect.DeepEqual(printed, want) {
+				t.Errorf("Excepted %q, got %q", want, printed)
+			}
+			if len(s.blinkers) != 0 {
+				t.Errorf("Excepted no blinkers, got %d", len(s.blinkers))
+			}
+		})
+	}
+}
+
+func TestBlinkRowNotOpened(t *testing.T) {
+	s := &Serial{proto: &mockProtocol{}}
+	notinit := "The device is not initialized"
+	if err := s.BlinkRow(1, time.Second); err == nil || err.Error() != notinit {
+		t.Errorf("Excepted error: %q, got: %v", notinit, err)
+	}
+}
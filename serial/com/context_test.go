@@ -0,0 +1,96 @@
+package com
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+var _ driver.ContextDisplay = &Serial{}
+
+func TestWriteContext(t *testing.T) {
+	release := make(chan struct{})
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		<-release
+		return len(b), nil
+	}
+	mprot := &mockProtocol{}
+	mprot.PrintRowCmdFn = func(row byte, text string) []byte {
+		return []byte(text)
+	}
+	mprot.ClearCmdFn = func() []byte {
+		return []byte{0x0c}
+	}
+	s := MustSerial(mprot)
+	s.CreatePort(mser)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.PrintRowContext(ctx, 1, "hung"); err != context.DeadlineExceeded {
+		t.Fatalf("Excepted %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// the next write waits for the pending one
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.SendContext(ctx, []byte{1}); err != context.DeadlineExceeded {
+		t.Fatalf("Excepted %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	close(release)
+	if err := s.Send([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := s.ClearContext(ctx); err != context.Canceled {
+		t.Errorf("Excepted %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestLockContext(t *testing.T) {
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(&mockSerialer{})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.InitContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Excepted %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestReceiveContext(t *testing.T) {
+	data := make(chan byte, 3)
+	mser := &mockSerialer{}
+	mser.ReadFn = func(b []byte) (int, error) {
+		b[0] = <-data
+		return 1, nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+
+	buf := make([]byte, 2)
+	data <- 1
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	n, err := s.ReceiveContext(ctx, buf)
+	if err != context.DeadlineExceeded || n != 1 || buf[0] != 1 {
+		t.Fatalf("Excepted 1 byte and %v, got %d byte(s) and %v", context.DeadlineExceeded, n, err)
+	}
+
+	// the interrupted read is completed by the next call
+	data <- 2
+	data <- 3
+	n, err = s.Receive(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || buf[0] != 2 || buf[1] != 3 {
+		t.Errorf("Excepted [2 3], got %v", buf[:n])
+	}
+}
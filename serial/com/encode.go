@@ -0,0 +1,194 @@
+package com
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/glyph"
+	"github.com/arteev/gold/rtl"
+)
+
+// Unencodable is the policy of the handling of the runes missing in the encoding
+type Unencodable int
+
+// Policies of the unencodable runes
+const (
+	// Replace writes the substitute instead of the rune
+	Replace Unencodable = iota
+	// Strict returns an error naming the rune
+	Strict
+	// Transliterate writes the rune with Latin letters (e.g. "é" as "e", "ß" as "ss",
+	// "Ж" as "Zh") or the substitute if there is no transliteration
+	Transliterate
+)
+
+var unencodableNames = map[string]Unencodable{
+	"replace":       Replace,
+	"strict":        Strict,
+	"transliterate": Transliterate,
+}
+
+// ParseUnencodable returns the policy by the name: replace, strict or transliterate
+func ParseUnencodable(name string) (Unencodable, error) {
+	policy, ok := unencodableNames[strings.ToLower(name)]
+	if !ok {
+		return Replace, fmt.Errorf("com: unknown unencodable policy %q", name)
+	}
+	return policy, nil
+}
+
+// encodingError is the error of the text which is not encodable, it matches driver.ErrEncoding
+type encodingError struct {
+	msg string
+}
+
+func (e *encodingError) Error() string {
+	return e.msg
+}
+
+func (e *encodingError) Unwrap() error {
+	return driver.ErrEncoding
+}
+
+// DefaultSubstitute is written instead of the unencodable runes
+const DefaultSubstitute = "?"
+
+// SetUnencodable sets the policy of the runes missing in the encoding.
+// An empty substitute means DefaultSubstitute.
+func (s *Serial) SetUnencodable(policy Unencodable, substitute string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+	s.subst = substitute
+}
+
+// SetBidi enables the reordering of the text from the logical order to the visual
+// one by the Unicode Bidirectional Algorithm for the right-to-left languages
+func (s *Serial) SetBidi(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bidi = enabled
+}
+
+func (s *Serial) encodetext(ctx context.Context, text string) (string, error) {
+	pinned := s.glyphs.visible(s.rows)
+	return s.encodewith(text, func(r rune) (byte, bool) {
+		return s.glyphcode(ctx, r, pinned)
+	})
+}
+
+// TextWidth returns the number of the cells of the text after the encoding,
+// the runes of the glyph library take a cell each
+func (s *Serial) TextWidth(text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	encoded, err := s.encodewith(text, func(r rune) (byte, bool) {
+		if s.glyphs == nil {
+			return 0, false
+		}
+		if _, ok := s.glyphs.chars[r]; ok {
+			return 0, true
+		}
+		_, ok := glyph.Lookup(r)
+		return 0, ok
+	})
+	if err != nil {
+		return utf8.RuneCountInString(text)
+	}
+	return s.cells(encoded)
+}
+
+// cells returns the number of the characters of the encoded text
+func (s *Serial) cells(encoded string) int {
+	if s.encoding == nil {
+		return utf8.RuneCountInString(encoded)
+	}
+	decoded, err := s.encoding.NewDecoder().String(encoded)
+	if err != nil {
+		return len(encoded)
+	}
+	return utf8.RuneCountInString(decoded)
+}
+
+// encodewith encodes the text, the runes missing in the encoding
+// are written by the code of the user-defined character got by glyphcode
+func (s *Serial) encodewith(text string, glyphcode func(rune) (byte, bool)) (string, error) {
+	if rtl.HasPresentationForms(s.encoding) {
+		text = rtl.Shape(text)
+	}
+	if s.bidi {
+		text = rtl.Reorder(text, rtl.Auto)
+	}
+	if s.encoding == nil && s.glyphs == nil {
+		return text, nil
+	}
+	var encode func(r rune) ([]byte, bool)
+	if s.encoding == nil {
+		// the text is not transcoded, only the runes of the glyph library are substituted
+		encode = func(r rune) ([]byte, bool) {
+			if _, ok := glyph.Lookup(r); ok && r >= utf8.RuneSelf {
+				return nil, false
+			}
+			return []byte(string(r)), true
+		}
+	} else {
+		enc := s.encoding.NewEncoder()
+		encode = func(r rune) ([]byte, bool) {
+			if r == utf8.RuneError {
+				return nil, false
+			}
+			b, err := enc.Bytes([]byte(string(r)))
+			return b, err == nil
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, r := range text {
+		if b, ok := encode(r); ok {
+			buf.Write(b)
+			continue
+		}
+		if code, ok := glyphcode(r); ok {
+			buf.WriteByte(code)
+			continue
+		}
+		if s.policy == Strict {
+			return "", &encodingError{fmt.Sprintf("com: rune %q (%U) is not encodable", r, r)}
+		}
+		if s.policy == Transliterate {
+			if b, ok := encodestring(transliterate(r), encode); ok {
+				buf.Write(b)
+				continue
+			}
+		}
+		subst := s.subst
+		if subst == "" {
+			subst = DefaultSubstitute
+		}
+		b, ok := encodestring(subst, encode)
+		if !ok {
+			return "", &encodingError{fmt.Sprintf("com: substitute %q is not encodable", subst)}
+		}
+		buf.Write(b)
+	}
+	return buf.String(), nil
+}
+
+func encodestring(text string, encode func(rune) ([]byte, bool)) ([]byte, bool) {
+	if text == "" {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	for _, r := range text {
+		b, ok := encode(r)
+		if !ok {
+			return nil, false
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,153 @@
+package com
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestUnencodable(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Policy Unencodable
+		Subst  string
+		Text   string
+		Want   string
+		Err    string
+	}{
+		{
+			Name:   "Replace",
+			Policy: Replace,
+			Text:   "Total:20€",
+			Want:   "Total:20?",
+		},
+		{
+			Name:   "ReplaceSubstitute",
+			Policy: Replace,
+			Subst:  "*",
+			Text:   "✓ Paid 😀",
+			Want:   "* Paid *",
+		},
+		{
+			Name:   "ReplaceUnencodableSubstitute",
+			Policy: Replace,
+			Subst:  "✓",
+			Text:   "€",
+			Err:    `com: substitute "✓" is not encodable`,
+		},
+		{
+			Name:   "Strict",
+			Policy: Strict,
+			Text:   "Total:20€",
+			Err:    `com: rune '€' (U+20AC) is not encodable`,
+		},
+		{
+			Name:   "StrictEncodable",
+			Policy: Strict,
+			Text:   "Price:10$",
+			Want:   "Price:10$",
+		},
+		{
+			Name:   "Transliterate",
+			Policy: Transliterate,
+			Text:   "Šťastný Łukasz №5 Щука 20€ ✓",
+			Want:   "Stastny Lukasz No5 Shchuka 20EUR ?",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			s := &Serial{}
+			s.SetEncoding(charmap.CodePage437)
+			s.SetUnencodable(c.Policy, c.Subst)
+			got, err := s.encodetext(context.Background(), c.Text)
+			if c.Err != "" {
+				if err == nil || err.Error() != c.Err || !errors.Is(err, driver.ErrEncoding) {
+					t.Errorf("Excepted error %q, got %v", c.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.Want {
+				t.Errorf("Excepted %q, got %q", c.Want, got)
+			}
+		})
+	}
+}
+
+func TestTransliterateEncodable(t *testing.T) {
+	s := &Serial{}
+	s.SetEncoding(charmap.CodePage866)
+	s.SetUnencodable(Transliterate, "")
+	want := []byte{0x8c, 0xa8, 0xe0, ' ', 'E', 'U', 'R'}
+	got, err := s.encodetext(context.Background(), "Мир €")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("Excepted %v, got %v", want, []byte(got))
+	}
+}
+
+func TestTransliterateASCII(t *testing.T) {
+	s := &Serial{}
+	s.SetEncoding(ASCII)
+	s.SetUnencodable(Transliterate, "")
+	got, err := s.encodetext(context.Background(), "Щука Łukasz 20€")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Shchuka Lukasz 20EUR"; got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+
+	// no encoding writes the text as is
+	s.SetEncoding(nil)
+	if got, _ := s.encodetext(context.Background(), "Щука 20€"); got != "Щука 20€" {
+		t.Errorf("Excepted the text as is, got %q", got)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	s := &Serial{}
+	if got := s.TextWidth("Щука №5"); got != 7 {
+		t.Errorf("Excepted 7 cells of the text as is, got %d", got)
+	}
+	s.SetEncoding(ASCII)
+	s.SetUnencodable(Transliterate, "")
+	if got := s.TextWidth("Щука №5"); got != 11 {
+		t.Errorf("Excepted 11 cells of %q, got %d", "Shchuka No5", got)
+	}
+	s.SetEncoding(charmap.CodePage866)
+	if got := s.TextWidth("Щука №5"); got != 7 {
+		t.Errorf("Excepted 7 cells, got %d", got)
+	}
+}
+
+func TestParseUnencodable(t *testing.T) {
+	if got, err := ParseUnencodable("Transliterate"); err != nil || got != Transliterate {
+		t.Errorf("Excepted %v, got %v, %v", Transliterate, got, err)
+	}
+	if _, err := ParseUnencodable("fake"); err == nil || err.Error() != `com: unknown unencodable policy "fake"` {
+		t.Errorf("Excepted error of unknown policy, got %v", err)
+	}
+}
+
+func TestBidi(t *testing.T) {
+	s := &Serial{}
+	s.SetEncoding(charmap.CodePage862)
+	s.SetBidi(true)
+	got, err := s.encodetext(context.Background(), "שלום 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "12 \x8d\x85\x8c\x99"; got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,187 @@
+package com
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/glyph"
+)
+
+// glyphSlots are the codes of the user-defined characters used for the runes
+// missing in the encoding. The least recently used rune is evicted when
+// there is no free code.
+type glyphSlots struct {
+	codes   []byte
+	chars   map[rune]byte
+	lru     []rune
+	enabled bool
+	// printed are the codes written by Print since the display was cleared,
+	// the position of such text is not tracked
+	printed map[byte]bool
+}
+
+// SetGlyphSlots sets the codes of the user-defined characters which show the runes
+// missing in the encoding. The glyph of such a rune is taken from the glyph library
+// and uploaded to a free code. The codes replace the characters of the code table,
+// so choose the codes which are not used in the text. No codes disable the substitution.
+func (s *Serial) SetGlyphSlots(codes ...byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(codes) == 0 {
+		s.glyphs = nil
+		return
+	}
+	s.glyphs = &glyphSlots{
+		codes: append([]byte(nil), codes...),
+		chars: make(map[rune]byte),
+	}
+}
+
+// glyphcode returns the code of the user-defined character showing the rune.
+// The pinned runes are used in the text being encoded and are not evicted.
+func (s *Serial) glyphcode(ctx context.Context, r rune, pinned map[rune]bool) (byte, bool) {
+	g := s.glyphs
+	if g == nil {
+		return 0, false
+	}
+	if code, ok := g.chars[r]; ok {
+		g.touch(r)
+		pinned[r] = true
+		return code, true
+	}
+	bitmap, ok := glyph.Lookup(r)
+	if !ok {
+		return 0, false
+	}
+	code, ok := g.alloc(r, pinned)
+	if !ok {
+		return 0, false
+	}
+	if !g.enabled {
+		err := s.sendFromProtocol(ctx, "UserChars", func() []byte {
+			return s.proto.UserCharsCmd(true)
+		}, true)
+		if err != nil && !errors.Is(err, driver.ErrNotSupported) {
+			g.release(r)
+			return 0, false
+		}
+		g.enabled = true
+	}
+	err := s.sendFromProtocol(ctx, "DefineChar", func() []byte {
+		return s.proto.DefineCharCmd(code, bitmap)
+	}, code, bitmap)
+	if err != nil {
+		g.release(r)
+		return 0, false
+	}
+	pinned[r] = true
+	return code, true
+}
+
+// visible returns the runes whose codes are shown in the rows printed by PrintRow
+// or written by Print, they are pinned so as not to change the glyphs on the display
+func (g *glyphSlots) visible(rows map[byte]printedRow) map[rune]bool {
+	pinned := make(map[rune]bool)
+	if g == nil {
+		return pinned
+	}
+	for r, code := range g.chars {
+		if g.printed[code] {
+			pinned[r] = true
+			continue
+		}
+		for _, printed := range rows {
+			if strings.IndexByte(printed.encoded, code) >= 0 {
+				pinned[r] = true
+				break
+			}
+		}
+	}
+	return pinned
+}
+
+// alloc returns a free code or the code of the least recently used rune
+// which is not pinned. No code is returned if all the runes are pinned.
+func (g *glyphSlots) alloc(r rune, pinned map[rune]bool) (byte, bool) {
+	if len(g.chars) < len(g.codes) {
+		used := make(map[byte]bool, len(g.chars))
+		for _, code := range g.chars {
+			used[code] = true
+		}
+		for _, code := range g.codes {
+			if !used[code] {
+				g.chars[r] = code
+				g.lru = append(g.lru, r)
+				return code, true
+			}
+		}
+	}
+	for i, old := range g.lru {
+		if pinned[old] {
+			continue
+		}
+		code := g.chars[old]
+		delete(g.chars, old)
+		g.lru = append(g.lru[:i], g.lru[i+1:]...)
+		g.chars[r] = code
+		g.lru = append(g.lru, r)
+		return code, true
+	}
+	return 0, false
+}
+
+func (g *glyphSlots) touch(r rune) {
+	for i, used := range g.lru {
+		if used == r {
+			g.lru = append(append(g.lru[:i], g.lru[i+1:]...), r)
+			return
+		}
+	}
+}
+
+func (g *glyphSlots) release(r rune) {
+	delete(g.chars, r)
+	for i, used := range g.lru {
+		if used == r {
+			g.lru = append(g.lru[:i], g.lru[i+1:]...)
+			return
+		}
+	}
+}
+
+// print marks the codes of the text written by Print as shown
+func (g *glyphSlots) print(text string) {
+	if g == nil {
+		return
+	}
+	for _, code := range g.codes {
+		if strings.IndexByte(text, code) < 0 {
+			continue
+		}
+		if g.printed == nil {
+			g.printed = make(map[byte]bool)
+		}
+		g.printed[code] = true
+	}
+}
+
+// clear forgets the codes written by Print, e.g. after the clearing of the display
+func (g *glyphSlots) clear() {
+	if g == nil {
+		return
+	}
+	g.printed = nil
+}
+
+// reset forgets the uploaded characters, e.g. after the initialization of the device
+func (g *glyphSlots) reset() {
+	if g == nil {
+		return
+	}
+	g.chars = make(map[rune]byte)
+	g.lru = nil
+	g.enabled = false
+	g.printed = nil
+}
@@ -0,0 +1,198 @@
+package com
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/arteev/gold/glyph"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestGlyphSubstitution(t *testing.T) {
+	mprot := &mockProtocol{}
+	mser := &mockSerialer{}
+	s := &Serial{proto: mprot}
+	s.CreatePort(mser)
+	s.SetEncoding(charmap.CodePage866)
+	s.SetGlyphSlots(0xf0, 0xf1)
+
+	var defined []byte
+	mprot.UserCharsCmdFn = func(bool) []byte {
+		return nil
+	}
+	mprot.DefineCharCmdFn = func(code byte, g []byte) []byte {
+		defined = append(defined, code)
+		return append([]byte{code}, g...)
+	}
+	mser.WriteFn = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+
+	got, err := s.encodetext(context.Background(), "10₽ 2€")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "10\xf0 2\xf1"; got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+	if !bytes.Equal(defined, []byte{0xf0, 0xf1}) {
+		t.Errorf("Excepted characters 0xf0,0xf1 to be defined, got %x", defined)
+	}
+
+	// uploaded glyphs are reused
+	defined = nil
+	if got, _ := s.encodetext(context.Background(), "₽"); got != "\xf0" || len(defined) != 0 {
+		t.Errorf("Excepted cached 0xf0, got %q, defined %x", got, defined)
+	}
+
+	// € is the least recently used
+	if got, _ := s.encodetext(context.Background(), "✓₽"); got != "\xf1\xf0" {
+		t.Errorf("Excepted %q, got %q", "\xf1\xf0", got)
+	}
+
+	// no free slots for the third rune of the text
+	s.SetUnencodable(Replace, "")
+	if got, _ := s.encodetext(context.Background(), "€✓₽"); got != "\xf1\xf0?" {
+		t.Errorf("Excepted %q, got %q", "\xf1\xf0?", got)
+	}
+
+	// no glyph in the library
+	if got, _ := s.encodetext(context.Background(), "😀"); got != "?" {
+		t.Errorf("Excepted %q, got %q", "?", got)
+	}
+}
+
+func TestGlyphSlotsVisibleRows(t *testing.T) {
+	mprot := &mockProtocol{}
+	mser := &mockSerialer{}
+	s := &Serial{proto: mprot}
+	s.CreatePort(mser)
+	s.SetEncoding(charmap.CodePage866)
+	s.SetGlyphSlots(0xf0, 0xf1)
+
+	var defined []byte
+	mprot.UserCharsCmdFn = func(bool) []byte {
+		return nil
+	}
+	mprot.DefineCharCmdFn = func(code byte, g []byte) []byte {
+		defined = append(defined, code)
+		return append([]byte{code}, g...)
+	}
+	mprot.PrintRowCmdFn = func(row byte, text string) []byte {
+		return []byte(text)
+	}
+	mser.WriteFn = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+
+	if err := s.PrintRow(1, "₽"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PrintRow(2, "€"); err != nil {
+		t.Fatal(err)
+	}
+	// the glyphs of the printed rows are not redefined
+	defined = nil
+	got, err := s.encodetext(context.Background(), "✓")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "?" || len(defined) != 0 {
+		t.Errorf("Excepted %q without definitions, got %q, defined %x", "?", got, defined)
+	}
+
+	// the glyph of the rewritten row is free
+	if err := s.PrintRow(2, "2"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.encodetext(context.Background(), "✓")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "\xf1" || !bytes.Equal(defined, []byte{0xf1}) {
+		t.Errorf("Excepted %q, got %q, defined %x", "\xf1", got, defined)
+	}
+}
+
+func TestGlyphSlotsPrinted(t *testing.T) {
+	mprot := &mockProtocol{}
+	mser := &mockSerialer{}
+	s := &Serial{proto: mprot}
+	s.CreatePort(mser)
+	s.SetEncoding(charmap.CodePage866)
+	s.SetGlyphSlots(0xf0)
+
+	var defined []byte
+	mprot.UserCharsCmdFn = func(bool) []byte {
+		return nil
+	}
+	mprot.DefineCharCmdFn = func(code byte, g []byte) []byte {
+		defined = append(defined, code)
+		return append([]byte{code}, g...)
+	}
+	mprot.PrintCmdFn = func(text string) []byte {
+		return []byte(text)
+	}
+	mprot.ClearCmdFn = func() []byte {
+		return []byte{0x0c}
+	}
+	mser.WriteFn = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+
+	if err := s.Print("10₽"); err != nil {
+		t.Fatal(err)
+	}
+	// the glyph written by Print is not redefined
+	defined = nil
+	if got, _ := s.encodetext(context.Background(), "€"); got != "?" || len(defined) != 0 {
+		t.Errorf("Excepted %q without definitions, got %q, defined %x", "?", got, defined)
+	}
+
+	// the glyph is free after the clearing
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.encodetext(context.Background(), "€"); got != "\xf0" || !bytes.Equal(defined, []byte{0xf0}) {
+		t.Errorf("Excepted %q, got %q, defined %x", "\xf0", got, defined)
+	}
+}
+
+func TestGlyphSlotsReset(t *testing.T) {
+	g := &glyphSlots{codes: []byte{1}, chars: make(map[rune]byte)}
+	if code, ok := g.alloc('€', nil); !ok || code != 1 {
+		t.Fatalf("Excepted code 1, got %d,%v", code, ok)
+	}
+	g.reset()
+	if len(g.chars) != 0 || len(g.lru) != 0 {
+		t.Errorf("Excepted no characters after reset, got %v", g.chars)
+	}
+	if _, ok := glyph.Lookup('€'); !ok {
+		t.Error("Excepted glyph of €")
+	}
+}
+
+func TestGlyphSubstitutionWithoutEncoding(t *testing.T) {
+	mprot := &mockProtocol{}
+	mser := &mockSerialer{}
+	s := &Serial{proto: mprot}
+	s.CreatePort(mser)
+	s.SetGlyphSlots(0x80)
+	mprot.UserCharsCmdFn = func(bool) []byte {
+		return []byte{0x0}
+	}
+	mprot.DefineCharCmdFn = func(code byte, g []byte) []byte {
+		return append([]byte{code}, g...)
+	}
+	mser.WriteFn = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+	got, err := s.encodetext(context.Background(), "Ж 5€")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Ж 5\x80"; got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,46 @@
+package com
+
+import (
+	"context"
+	"sync"
+)
+
+// ctxMutex is a mutex whose locking can be abandoned when the context is done.
+// The zero value is an unlocked mutex.
+type ctxMutex struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func (m *ctxMutex) init() {
+	m.once.Do(func() {
+		m.ch = make(chan struct{}, 1)
+	})
+}
+
+func (m *ctxMutex) Lock() {
+	m.init()
+	m.ch <- struct{}{}
+}
+
+// LockContext locks the mutex or returns the error of the context
+func (m *ctxMutex) LockContext(ctx context.Context) error {
+	m.init()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *ctxMutex) Unlock() {
+	select {
+	case <-m.ch:
+	default:
+		panic("com: unlock of unlocked mutex")
+	}
+}
@@ -0,0 +1,19 @@
+package com
+
+import (
+	"github.com/arteev/gold/driver"
+)
+
+// AddObserver adds the observer of the commands sent to the device
+// and the data received from it
+func (s *Serial) AddObserver(o driver.Observer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, o)
+}
+
+func (s *Serial) notify(e driver.Event) {
+	for _, o := range s.observers {
+		o.Observe(e)
+	}
+}
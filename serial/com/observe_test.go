@@ -0,0 +1,71 @@
+package com
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/arteev/gold/driver"
+)
+
+func TestObserver(t *testing.T) {
+	mprot := &mockProtocol{}
+	mprot.PrintRowCmdFn = func(row byte, text string) []byte {
+		return append([]byte{0x1b, row}, text...)
+	}
+	mprot.ClearCmdFn = func() []byte {
+		return nil
+	}
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+	mser.ReadFn = func(b []byte) (int, error) {
+		return copy(b, "ok"), nil
+	}
+	s := MustSerial(mprot)
+	s.CreatePort(mser)
+	var events []driver.Event
+	s.AddObserver(driver.ObserverFunc(func(e driver.Event) {
+		events = append(events, e)
+	}))
+
+	s.PrintRow(1, "Milk")
+	s.Clear()
+	s.Receive(make([]byte, 2))
+	if len(events) != 3 {
+		t.Fatalf("Excepted 3 events, got %d", len(events))
+	}
+
+	e := events[0]
+	if e.Command != "PrintRow" || len(e.Args) != 2 || e.Args[0] != byte(1) || e.Args[1] != "Milk" ||
+		!bytes.Equal(e.Data, []byte("\x1b\x01Milk")) || e.Direction != driver.Write || e.Err != nil {
+		t.Errorf("Excepted event of PrintRow, got %v", e)
+	}
+	if got, want := e.String(), `PrintRow(1, "Milk") write 1b 01 4d 69 6c 6b in `+e.Duration.String(); got != want {
+		t.Errorf("Excepted %q, got %q", want, got)
+	}
+	if e := events[1]; e.Command != "Clear" || !errors.Is(e.Err, driver.ErrNotSupported) {
+		t.Errorf("Excepted event of unsupported Clear, got %v", e)
+	}
+	if e := events[2]; e.Command != "Receive" || e.Direction != driver.Read || string(e.Data) != "ok" {
+		t.Errorf("Excepted event of Receive, got %v", e)
+	}
+}
+
+func TestObserveReconnect(t *testing.T) {
+	s := MustSerial(&mockProtocol{})
+	var events []driver.Event
+	s.AddObserver(driver.ObserverFunc(func(e driver.Event) {
+		events = append(events, e)
+	}))
+
+	s.CreatePort(&mockSerialer{})
+	if len(events) != 0 {
+		t.Fatalf("Excepted no events of the first port, got %v", events)
+	}
+	s.CreatePort(&mockSerialer{})
+	if len(events) != 1 || events[0].Command != driver.Reconnect {
+		t.Errorf("Excepted event of Reconnect, got %v", events)
+	}
+}
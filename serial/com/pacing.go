@@ -0,0 +1,50 @@
+package com
+
+import (
+	"context"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// SetTiming sets the pacing of the commands. By default it is the timing
+// of the protocol implementing driver.Paced.
+func (s *Serial) SetTiming(timing driver.Timing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timing = timing
+}
+
+// Timing returns the pacing of the commands
+func (s *Serial) Timing() driver.Timing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timing
+}
+
+// pace waits until the device is ready and reserves the time
+// of the transfer of n bytes
+func (s *Serial) pace(ctx context.Context, n int) error {
+	if wait := time.Until(s.ready); wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	start := time.Now()
+	if s.timing.BytesPerSecond > 0 {
+		start = start.Add(time.Duration(n) * time.Second / time.Duration(s.timing.BytesPerSecond))
+	}
+	s.ready = start
+	return nil
+}
+
+// delay postpones the next write
+func (s *Serial) delay(d time.Duration) {
+	if ready := time.Now().Add(d); ready.After(s.ready) {
+		s.ready = ready
+	}
+}
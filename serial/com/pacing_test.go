@@ -0,0 +1,57 @@
+package com
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockPacedProtocol struct {
+	*mockProtocol
+	timing driver.Timing
+}
+
+func (m mockPacedProtocol) Timing() driver.Timing {
+	return m.timing
+}
+
+func TestPacing(t *testing.T) {
+	mprot := &mockProtocol{}
+	mprot.ClearCmdFn = func() []byte {
+		return []byte{0x0c}
+	}
+	var written []time.Time
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		written = append(written, time.Now())
+		return len(b), nil
+	}
+	s := MustSerial(mockPacedProtocol{mprot, driver.Timing{AfterClear: 30 * time.Millisecond}})
+	s.CreatePort(mser)
+	if got := s.Timing().AfterClear; got != 30*time.Millisecond {
+		t.Fatalf("Excepted timing of the protocol, got %v", got)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if d := written[1].Sub(written[0]); d < 30*time.Millisecond {
+		t.Errorf("Excepted delay after clear 30ms, got %v", d)
+	}
+
+	s.SetTiming(driver.Timing{BytesPerSecond: 1000})
+	written = nil
+	if err := s.Send(make([]byte, 40)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if d := written[1].Sub(written[0]); d < 40*time.Millisecond {
+		t.Errorf("Excepted 40 bytes written in 40ms, got %v", d)
+	}
+}
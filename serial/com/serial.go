@@ -1,25 +1,52 @@
 package com
 
 import (
-	"errors"
-	"fmt"
-	"io/ioutil"
-	"strings"
+	"context"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/arteev/gold/driver"
 
 	"golang.org/x/text/encoding"
-	"golang.org/x/text/transform"
 )
 
 type Serial struct {
-	mu       sync.Mutex
+	mu       ctxMutex
 	opened   bool
 	encoding encoding.Encoding
+	policy   Unencodable
+	subst    string
+	glyphs   *glyphSlots
+	bidi     bool
+	rows     map[byte]printedRow
+
+	// selectTable enables sending the code table of the encoding,
+	// tablePending is set until the table is sent
+	selectTable  bool
+	tablePending bool
 
 	port  Serialer
 	proto driver.Protocol
+
+	muBlink  sync.Mutex
+	blinkers map[byte]*blinker
+	// ticker returns the ticks of the software blink, nil means time.NewTicker
+	ticker func(interval time.Duration) (ticks <-chan time.Time, stop func())
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	timing       driver.Timing
+	observers    []driver.Observer
+	// ident is the identification of the device got by Status
+	ident *driver.Status
+	// ready is the time of the next write allowed by the timing
+	ready time.Time
+
+	// pending is the write abandoned by the canceled context
+	pending chan error
+	reading chan readResult
+	unread  []byte
 }
 type Serialer interface {
 	Write(b []byte) (n int, err error)
@@ -28,61 +55,145 @@ type Serialer interface {
 }
 
 func MustSerial(protocol driver.Protocol) *Serial {
-	return &Serial{
+	s := &Serial{
 		proto: protocol,
 	}
+	if paced, ok := protocol.(driver.Paced); ok {
+		s.timing = paced.Timing()
+	}
+	return s
 }
 
 func (s *Serial) check() error {
 	if !s.opened {
-		return errors.New("The device is not initialized")
+		return driver.ErrNotOpen
 	}
 	return nil
 }
 
-func (s *Serial) send(data []byte) error {
+// send writes the data to the port. If the context is done or the write timeout
+// expires before the write completes, the write is left pending
+// and the next send waits for it.
+func (s *Serial) send(parent context.Context, data []byte) error {
 	if err := s.check(); err != nil {
 		return err
 	}
+	if err := s.pace(parent, len(data)); err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(parent, s.writeTimeout)
+	defer cancel()
+	err := s.sendContext(ctx, data)
+	return timeoutError(parent, err, "write", s.writeTimeout)
+}
+
+func (s *Serial) sendContext(ctx context.Context, data []byte) error {
+	if err := s.waitPending(ctx); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return s.write(data)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.write(data)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		s.pending = done
+		return ctx.Err()
+	}
+}
+
+func (s *Serial) waitPending(ctx context.Context) error {
+	if s.pending == nil {
+		return nil
+	}
+	select {
+	case <-s.pending:
+		s.pending = nil
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Serial) write(data []byte) error {
 	n, err := s.port.Write(data)
 	if err != nil {
 		return err
 	}
 	if len(data) != n {
-		return fmt.Errorf("Error write: must %d byte(s), but %d byte(s)", n, len(data))
+		return &driver.ShortWriteError{Want: len(data), Written: n}
 	}
 	return nil
 }
-func (s *Serial) sendFromProtocol(fn func() []byte) error {
+
+// sendFromProtocol sends the command built by the protocol.
+// The name and the arguments of the command are reported to the observers,
+// the name is reported in the ProtocolError.
+func (s *Serial) sendFromProtocol(ctx context.Context, name string, fn func() []byte, args ...interface{}) error {
 	data := fn()
 	if len(data) == 0 {
-		return driver.ErrNotSupported
+		err := &driver.ProtocolError{Command: name, Err: driver.ErrNotSupported}
+		s.notify(driver.Event{Time: time.Now(), Command: name, Args: args, Err: err})
+		return err
 	}
-	return s.send(data)
+	return s.sendCommand(ctx, name, data, args...)
 }
 
-func (s *Serial) encodetext(text string) (string, error) {
-	if s.encoding == nil {
-		return text, nil
+// sendCommand sends the data and reports the event to the observers
+func (s *Serial) sendCommand(ctx context.Context, name string, data []byte, args ...interface{}) error {
+	start := time.Now()
+	err := s.send(ctx, data)
+	s.notify(driver.Event{
+		Time:      start,
+		Command:   name,
+		Args:      args,
+		Data:      data,
+		Direction: driver.Write,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+	return err
+}
+
+// checkRow returns ErrInvalidRow if the protocol knows the size
+// of the display and the row is out of it
+func (s *Serial) checkRow(name string, row byte) error {
+	sizer, ok := s.proto.(driver.Sizer)
+	if !ok {
+		return nil
 	}
-	sr := strings.NewReader(text)
-	tr := transform.NewReader(sr, s.encoding.NewEncoder())
-	buf, err := ioutil.ReadAll(tr)
-	if err != err {
-		return "", err
+	if rows, _ := sizer.Size(); row < 1 || row > rows {
+		return &driver.ProtocolError{Command: name, Err: driver.ErrInvalidRow}
 	}
-	return string(buf), nil
+	return nil
 }
 
 /////
+// CreatePort sets the port of the device. Creating the port again
+// is reported to the observers as the driver.Reconnect event.
 func (s *Serial) CreatePort(port Serialer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reconnect := s.port != nil
 	s.port = port
 	s.opened = true
+	if reconnect {
+		s.notify(driver.Event{Time: time.Now(), Command: driver.Reconnect})
+	}
 }
 
 /////
 
 func (s *Serial) Close() error {
+	s.stopBlinkers()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if err := s.check(); err != nil {
@@ -91,164 +202,547 @@ func (s *Serial) Close() error {
 	err := s.port.Close()
 	if err == nil {
 		s.opened = false
+		s.pending = nil
+		s.reading = nil
+		s.unread = nil
+		s.ident = nil
 	}
 	return err
 }
+
+// SetEncoding sets the encoding of the text. Nil writes the text as is,
+// use ASCII to handle the runes missing in ASCII by the policy set by SetUnencodable.
+// The code table of the device is selected if enabled by SetSelectCodeTable.
 func (s *Serial) SetEncoding(encoding encoding.Encoding) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.encoding = encoding
+	s.tablePending = s.selectTable && encoding != nil
 }
 func (s *Serial) Init() error {
-	s.mu.Lock()
+	return s.InitContext(context.Background())
+}
+func (s *Serial) InitContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.InitCmd)
+	if err := s.sendFromProtocol(ctx, "Init", s.proto.InitCmd); err != nil {
+		return err
+	}
+	s.delay(s.timing.AfterInit)
+	s.glyphs.reset()
+	s.tablePending = s.selectTable && s.encoding != nil
+	return nil
 }
 func (s *Serial) Test() error {
-	s.mu.Lock()
+	return s.TestContext(context.Background())
+}
+func (s *Serial) TestContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.TestCmd)
+	return s.sendFromProtocol(ctx, "Test", s.proto.TestCmd)
 }
 func (s *Serial) Clear() error {
-	s.mu.Lock()
+	return s.ClearContext(context.Background())
+}
+func (s *Serial) ClearContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.ClearCmd)
+	if err := s.sendFromProtocol(ctx, "Clear", s.proto.ClearCmd); err != nil {
+		return err
+	}
+	s.delay(s.timing.AfterClear)
+	s.rows = nil
+	s.glyphs.clear()
+	return nil
 }
 func (s *Serial) ClearRow() error {
-	s.mu.Lock()
+	return s.ClearRowContext(context.Background())
+}
+func (s *Serial) ClearRowContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.ClearRowCmd)
+	return s.sendFromProtocol(ctx, "ClearRow", s.proto.ClearRowCmd)
 }
 
 func (s *Serial) CursorVisible(visible bool) error {
-	s.mu.Lock()
+	return s.CursorVisibleContext(context.Background(), visible)
+}
+func (s *Serial) CursorVisibleContext(ctx context.Context, visible bool) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
 	fn := func() []byte {
 		return s.proto.CursorVisibleCmd(visible)
 	}
-	return s.sendFromProtocol(fn)
+	return s.sendFromProtocol(ctx, "CursorVisible", fn, visible)
 }
 func (s *Serial) ModeRewrite() error {
-	s.mu.Lock()
+	return s.ModeRewriteContext(context.Background())
+}
+func (s *Serial) ModeRewriteContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.ModeRewriteCmd)
+	return s.sendFromProtocol(ctx, "ModeRewrite", s.proto.ModeRewriteCmd)
 }
 func (s *Serial) ModeVScroll() error {
-	s.mu.Lock()
+	return s.ModeVScrollContext(context.Background())
+}
+func (s *Serial) ModeVScrollContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.ModeVScrollCmd)
+	return s.sendFromProtocol(ctx, "ModeVScroll", s.proto.ModeVScrollCmd)
 }
 func (s *Serial) ModeHScroll() error {
-	s.mu.Lock()
+	return s.ModeHScrollContext(context.Background())
+}
+func (s *Serial) ModeHScrollContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.ModeHScrollCmd)
+	return s.sendFromProtocol(ctx, "ModeHScroll", s.proto.ModeHScrollCmd)
 }
 func (s *Serial) Brightness(value byte) error {
-	s.mu.Lock()
+	return s.BrightnessContext(context.Background(), value)
+}
+func (s *Serial) BrightnessContext(ctx context.Context, value byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
 	fn := func() []byte {
 		return s.proto.BrightnessCmd(value)
 	}
-	return s.sendFromProtocol(fn)
+	if err := s.sendFromProtocol(ctx, "Brightness", fn, value); err != nil {
+		return err
+	}
+	s.delay(s.timing.AfterBrightness)
+	return nil
 }
 
 func (s *Serial) PrintRow(row byte, text string) error {
-	s.mu.Lock()
+	return s.PrintRowContext(context.Background(), row, text)
+}
+func (s *Serial) PrintRowContext(ctx context.Context, row byte, text string) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
+	if err := s.checkRow("PrintRow", row); err != nil {
+		return err
+	}
 
-	outtext, err := s.encodetext(text)
+	outtext, err := s.encodetext(ctx, text)
 	if err != nil {
 		return err
 	}
+	if err := s.sendCodeTable(ctx); err != nil {
+		return err
+	}
 	fn := func() []byte {
 		return s.proto.PrintRowCmd(row, outtext)
 	}
-	return s.sendFromProtocol(fn)
+	if err := s.sendFromProtocol(ctx, "PrintRow", fn, row, text); err != nil {
+		return err
+	}
+	if s.rows == nil {
+		s.rows = make(map[byte]printedRow)
+	}
+	s.rows[row] = printedRow{encoded: outtext, width: s.cells(outtext)}
+	return nil
+}
+
+func (s *Serial) Print(text string) error {
+	return s.PrintContext(context.Background(), text)
+}
+func (s *Serial) PrintContext(ctx context.Context, text string) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	outtext, err := s.encodetext(ctx, text)
+	if err != nil {
+		return err
+	}
+	if err := s.sendCodeTable(ctx); err != nil {
+		return err
+	}
+	fn := func() []byte {
+		return s.proto.PrintCmd(outtext)
+	}
+	if err := s.sendFromProtocol(ctx, "Print", fn, text); err != nil {
+		return err
+	}
+	s.glyphs.print(outtext)
+	return nil
+}
+
+// CharSize sets the size of the characters as multiple of the normal size
+func (s *Serial) CharSize(width, height byte) error {
+	return s.CharSizeContext(context.Background(), width, height)
+}
+func (s *Serial) CharSizeContext(ctx context.Context, width, height byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.CharSizeCmd(width, height)
+	}
+	return s.sendFromProtocol(ctx, "CharSize", fn, width, height)
 }
 
 func (s *Serial) CursorMoveUp() error {
-	s.mu.Lock()
+	return s.CursorMoveUpContext(context.Background())
+}
+func (s *Serial) CursorMoveUpContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveUpCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveUp", s.proto.CursorMoveUpCmd)
 }
 func (s *Serial) CursorMoveDown() error {
-	s.mu.Lock()
+	return s.CursorMoveDownContext(context.Background())
+}
+func (s *Serial) CursorMoveDownContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveDownCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveDown", s.proto.CursorMoveDownCmd)
 }
 func (s *Serial) CursorMoveRight() error {
-	s.mu.Lock()
+	return s.CursorMoveRightContext(context.Background())
+}
+func (s *Serial) CursorMoveRightContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveRightCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveRight", s.proto.CursorMoveRightCmd)
 }
 func (s *Serial) CursorMoveLeft() error {
-	s.mu.Lock()
+	return s.CursorMoveLeftContext(context.Background())
+}
+func (s *Serial) CursorMoveLeftContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveLeftCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveLeft", s.proto.CursorMoveLeftCmd)
 }
 
 func (s *Serial) CursorMoveLeftTop() error {
-	s.mu.Lock()
+	return s.CursorMoveLeftTopContext(context.Background())
+}
+func (s *Serial) CursorMoveLeftTopContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveLeftTopCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveLeftTop", s.proto.CursorMoveLeftTopCmd)
 }
 func (s *Serial) CursorMoveBeginInRow() error {
-	s.mu.Lock()
+	return s.CursorMoveBeginInRowContext(context.Background())
+}
+func (s *Serial) CursorMoveBeginInRowContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveBeginInRowCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveBeginInRow", s.proto.CursorMoveBeginInRowCmd)
 }
 func (s *Serial) CursorMoveEndInRow() error {
-	s.mu.Lock()
+	return s.CursorMoveEndInRowContext(context.Background())
+}
+func (s *Serial) CursorMoveEndInRowContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveEndInRowCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveEndInRow", s.proto.CursorMoveEndInRowCmd)
 }
 func (s *Serial) CursorMoveBottom() error {
-	s.mu.Lock()
+	return s.CursorMoveBottomContext(context.Background())
+}
+func (s *Serial) CursorMoveBottomContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.CursorMoveBottomCmd)
+	return s.sendFromProtocol(ctx, "CursorMoveBottom", s.proto.CursorMoveBottomCmd)
 }
 func (s *Serial) CursorMove(row, col byte) error {
-	s.mu.Lock()
+	return s.CursorMoveContext(context.Background(), row, col)
+}
+func (s *Serial) CursorMoveContext(ctx context.Context, row, col byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
 	fn := func() []byte {
 		return s.proto.CursorMoveCmd(row, col)
 	}
-	return s.sendFromProtocol(fn)
+	return s.sendFromProtocol(ctx, "CursorMove", fn, row, col)
 }
 
 func (s *Serial) FlagEnable(enabled bool, num byte) error {
-	s.mu.Lock()
+	return s.FlagEnableContext(context.Background(), enabled, num)
+}
+func (s *Serial) FlagEnableContext(ctx context.Context, enabled bool, num byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
 	fn := func() []byte {
 		return s.proto.FlagEnableCmd(enabled, num)
 	}
-	return s.sendFromProtocol(fn)
+	return s.sendFromProtocol(ctx, "FlagEnable", fn, enabled, num)
 }
 func (s *Serial) FlagsDisable() error {
+	return s.FlagsDisableContext(context.Background())
+}
+func (s *Serial) FlagsDisableContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	return s.sendFromProtocol(ctx, "FlagsDisable", s.proto.FlagsDisableCmd)
+}
+
+func (s *Serial) SetTime(t time.Time) error {
+	return s.SetTimeContext(context.Background(), t)
+}
+func (s *Serial) SetTimeContext(ctx context.Context, t time.Time) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.SetTimeCmd(byte(t.Hour()), byte(t.Minute()))
+	}
+	return s.sendFromProtocol(ctx, "SetTime", fn, t)
+}
+func (s *Serial) ShowClock() error {
+	return s.ShowClockContext(context.Background())
+}
+func (s *Serial) ShowClockContext(ctx context.Context) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	return s.sendFromProtocol(ctx, "ShowClock", s.proto.ShowClockCmd)
+}
+
+func (s *Serial) SetCodeTable(table byte) error {
+	return s.SetCodeTableContext(context.Background(), table)
+}
+func (s *Serial) SetCodeTableContext(ctx context.Context, table byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.CodeTableCmd(table)
+	}
+	return s.sendFromProtocol(ctx, "SetCodeTable", fn, table)
+}
+func (s *Serial) SetCharset(charset byte) error {
+	return s.SetCharsetContext(context.Background(), charset)
+}
+func (s *Serial) SetCharsetContext(ctx context.Context, charset byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.CharsetCmd(charset)
+	}
+	return s.sendFromProtocol(ctx, "SetCharset", fn, charset)
+}
+
+// SetSelectCodeTable enables selecting the code table of the device matching
+// the encoding set by SetEncoding. The table is sent before the next text
+// and again after Init. If the protocol does not know the table of the encoding,
+// the next text returns ErrNotSupported once.
+func (s *Serial) SetSelectCodeTable(enabled bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.sendFromProtocol(s.proto.FlagsDisableCmd)
+	s.selectTable = enabled
+	s.tablePending = enabled && s.encoding != nil
+}
+
+// sendCodeTable sends the code table of the encoding if it is pending
+func (s *Serial) sendCodeTable(ctx context.Context) error {
+	if !s.tablePending {
+		return nil
+	}
+	tabler, ok := s.proto.(driver.CodeTabler)
+	var table byte
+	if ok {
+		table, ok = tabler.CodeTable(s.encoding)
+	}
+	if !ok {
+		s.tablePending = false
+		return &driver.ProtocolError{Command: "SetCodeTable", Err: driver.ErrNotSupported}
+	}
+	fn := func() []byte {
+		return s.proto.CodeTableCmd(table)
+	}
+	if err := s.sendFromProtocol(ctx, "SetCodeTable", fn, table); err != nil {
+		return err
+	}
+	s.tablePending = false
+	return nil
+}
+
+func (s *Serial) DefineChar(code byte, glyph []byte) error {
+	return s.DefineCharContext(context.Background(), code, glyph)
+}
+func (s *Serial) DefineCharContext(ctx context.Context, code byte, glyph []byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.DefineCharCmd(code, glyph)
+	}
+	return s.sendFromProtocol(ctx, "DefineChar", fn, code, glyph)
+}
+func (s *Serial) UserChars(enabled bool) error {
+	return s.UserCharsContext(context.Background(), enabled)
+}
+func (s *Serial) UserCharsContext(ctx context.Context, enabled bool) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	fn := func() []byte {
+		return s.proto.UserCharsCmd(enabled)
+	}
+	return s.sendFromProtocol(ctx, "UserChars", fn, enabled)
 }
 
 func (s *Serial) Send(data []byte) error {
-	s.mu.Lock()
+	return s.SendContext(context.Background(), data)
+}
+func (s *Serial) SendContext(ctx context.Context, data []byte) error {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.mu.Unlock()
-	return s.send(data)
+	return s.sendCommand(ctx, "Send", data)
 }
 
+// Receive reads from the device until b is full or the read timeout expires
 func (s *Serial) Receive(b []byte) (n int, err error) {
-	n, err = s.port.Read(b)
-	if err != nil {
+	return s.ReceiveContext(context.Background(), b)
+}
+
+// ReceiveContext is like Receive but returns when the context is done.
+// The bytes of the read interrupted by the context or the timeout are returned by the next call.
+func (s *Serial) ReceiveContext(ctx context.Context, b []byte) (n int, err error) {
+	if err := s.mu.LockContext(ctx); err != nil {
 		return 0, err
 	}
-	if n < len(b) {
+	defer s.mu.Unlock()
+	return s.receiveCommand(ctx, "Receive", b, s.readTimeout, 0)
+}
 
-		nn, err := s.Receive(b[n:])
-		return n + nn, err
+// receiveCommand receives the data and reports the event to the observers
+func (s *Serial) receiveCommand(ctx context.Context, name string, b []byte, timeout, gap time.Duration) (int, error) {
+	start := time.Now()
+	n, err := s.receive(ctx, b, timeout, gap)
+	s.notify(driver.Event{
+		Time:      start,
+		Command:   name,
+		Data:      b[:n],
+		Direction: driver.Read,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+	return n, err
+}
+
+// receive reads len(b) bytes. If the gap is set, the answer ends
+// when no byte comes for the gap after the first one.
+func (s *Serial) receive(parent context.Context, b []byte, timeout, gap time.Duration) (n int, err error) {
+	if err := s.check(); err != nil {
+		return 0, err
+	}
+	ctx, cancel := withTimeout(parent, timeout)
+	defer cancel()
+	for n < len(b) {
+		if len(s.unread) == 0 {
+			if gap > 0 && n > 0 {
+				var ended bool
+				if ended, err = s.readGap(ctx, len(b)-n, gap); ended {
+					return n, nil
+				}
+			} else {
+				err = s.read(ctx, len(b)-n)
+			}
+			if err == nil && len(s.unread) == 0 {
+				// the port has returned nothing without an error
+				err = s.idle(ctx)
+			}
+		}
+		nn := copy(b[n:], s.unread)
+		s.unread = s.unread[nn:]
+		n += nn
+		if err != nil {
+			return n, timeoutError(parent, err, "read", timeout)
+		}
 	}
 	return n, nil
 }
 
+// readGap reads the next bytes of the answer and reports
+// whether the answer has ended: no byte has come for the gap
+func (s *Serial) readGap(ctx context.Context, size int, gap time.Duration) (bool, error) {
+	gapCtx, cancel := context.WithTimeout(ctx, gap)
+	defer cancel()
+	err := s.read(gapCtx, size)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return true, nil
+	}
+	return false, err
+}
+
+// readPoll is the pause after the read of no data before the next read
+const readPoll = 10 * time.Millisecond
+
+// idle waits before the next read after the read of no data. Without
+// the deadline the read would never end, io.ErrNoProgress is returned.
+func (s *Serial) idle(ctx context.Context) error {
+	if ctx.Done() == nil {
+		return io.ErrNoProgress
+	}
+	timer := time.NewTimer(readPoll)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *Serial) Protocol() driver.Protocol {
 	return s.proto
 }
@@ -256,3 +750,39 @@ func (s *Serial) Protocol() driver.Protocol {
 func (s *Serial) Serialer() Serialer {
 	return s.port
 }
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// read fills s.unread with up to size bytes. The read interrupted
+// by the context stays pending until the next call.
+func (s *Serial) read(ctx context.Context, size int) error {
+	if s.reading == nil {
+		if ctx.Done() == nil {
+			buf := make([]byte, size)
+			n, err := s.port.Read(buf)
+			s.unread = buf[:n]
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reading := make(chan readResult, 1)
+		go func() {
+			buf := make([]byte, size)
+			n, err := s.port.Read(buf)
+			reading <- readResult{buf[:n], err}
+		}()
+		s.reading = reading
+	}
+	select {
+	case r := <-s.reading:
+		s.reading = nil
+		s.unread = r.data
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,13 +1,17 @@
 package com
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"bytes"
 
 	"github.com/arteev/gold/driver"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
 )
 
@@ -76,6 +80,12 @@ type mockProtocol struct {
 	PrintRowCmdFn      func(byte, string) []byte
 	PrintRowCmdInvoked bool
 
+	PrintCmdFn      func(string) []byte
+	PrintCmdInvoked bool
+
+	CharSizeCmdFn      func(width, height byte) []byte
+	CharSizeCmdInvoked bool
+
 	CursorMoveUpCmdFn      func() []byte
 	CursorMoveUpCmdInvoked bool
 
@@ -108,6 +118,33 @@ type mockProtocol struct {
 
 	FlagsDisableCmdFn      func() []byte
 	FlagsDisableCmdInvoked bool
+
+	SetTimeCmdFn      func(hour, minute byte) []byte
+	SetTimeCmdInvoked bool
+
+	ShowClockCmdFn      func() []byte
+	ShowClockCmdInvoked bool
+
+	BlinkCmdFn      func(time.Duration) []byte
+	BlinkCmdInvoked bool
+
+	ReverseCmdFn      func(bool) []byte
+	ReverseCmdInvoked bool
+
+	UnderlineCmdFn      func(bool) []byte
+	UnderlineCmdInvoked bool
+
+	CodeTableCmdFn      func(byte) []byte
+	CodeTableCmdInvoked bool
+
+	CharsetCmdFn      func(byte) []byte
+	CharsetCmdInvoked bool
+
+	DefineCharCmdFn      func(byte, []byte) []byte
+	DefineCharCmdInvoked bool
+
+	UserCharsCmdFn      func(bool) []byte
+	UserCharsCmdInvoked bool
 }
 
 func (m *mockProtocol) InitCmd() []byte {
@@ -153,6 +190,16 @@ func (m *mockProtocol) PrintRowCmd(row byte, text string) []byte {
 	return m.PrintRowCmdFn(row, text)
 }
 
+func (m *mockProtocol) PrintCmd(text string) []byte {
+	m.PrintCmdInvoked = true
+	return m.PrintCmdFn(text)
+}
+
+func (m *mockProtocol) CharSizeCmd(width, height byte) []byte {
+	m.CharSizeCmdInvoked = true
+	return m.CharSizeCmdFn(width, height)
+}
+
 func (m *mockProtocol) CursorMoveUpCmd() []byte {
 	m.CursorMoveUpCmdInvoked = true
 	return m.CursorMoveUpCmdFn()
@@ -199,13 +246,63 @@ func (m *mockProtocol) FlagsDisableCmd() []byte {
 	return m.FlagsDisableCmdFn()
 }
 
+func (m *mockProtocol) SetTimeCmd(hour, minute byte) []byte {
+	m.SetTimeCmdInvoked = true
+	return m.SetTimeCmdFn(hour, minute)
+}
+func (m *mockProtocol) ShowClockCmd() []byte {
+	m.ShowClockCmdInvoked = true
+	return m.ShowClockCmdFn()
+}
+
+func (m *mockProtocol) BlinkCmd(interval time.Duration) []byte {
+	m.BlinkCmdInvoked = true
+	return m.BlinkCmdFn(interval)
+}
+func (m *mockProtocol) ReverseCmd(enabled bool) []byte {
+	m.ReverseCmdInvoked = true
+	return m.ReverseCmdFn(enabled)
+}
+func (m *mockProtocol) UnderlineCmd(enabled bool) []byte {
+	m.UnderlineCmdInvoked = true
+	return m.UnderlineCmdFn(enabled)
+}
+
+func (m *mockProtocol) CodeTableCmd(table byte) []byte {
+	m.CodeTableCmdInvoked = true
+	return m.CodeTableCmdFn(table)
+}
+func (m *mockProtocol) CharsetCmd(charset byte) []byte {
+	m.CharsetCmdInvoked = true
+	return m.CharsetCmdFn(charset)
+}
+
+func (m *mockProtocol) DefineCharCmd(code byte, glyph []byte) []byte {
+	m.DefineCharCmdInvoked = true
+	return m.DefineCharCmdFn(code, glyph)
+}
+func (m *mockProtocol) UserCharsCmd(enabled bool) []byte {
+	m.UserCharsCmdInvoked = true
+	return m.UserCharsCmdFn(enabled)
+}
+
+type mockCodeTabler struct {
+	*mockProtocol
+	tables map[encoding.Encoding]byte
+}
+
+func (m mockCodeTabler) CodeTable(enc encoding.Encoding) (byte, bool) {
+	table, ok := m.tables[enc]
+	return table, ok
+}
+
 func TestCreateAndClose(t *testing.T) {
 	mprot := &mockProtocol{}
 	mser := &mockSerialer{}
 	s := MustSerial(mprot)
 
 	notinit := "The device is not initialized"
-	if err := s.check(); err == nil || err.Error() != notinit {
+	if err := s.check(); err == nil || err.Error() != notinit || !errors.Is(err, driver.ErrNotOpen) {
 		t.Fatalf("Excepted:The device is not initialized. Got: %v", err)
 	}
 	s.CreatePort(mser)
@@ -213,10 +310,10 @@ func TestCreateAndClose(t *testing.T) {
 		t.Fatalf("Excepted:check()=nil,got:%q", err)
 	}
 	if got := s.Protocol(); got != mprot {
-		t.Fatalf("Excepted protocol %q, got %q", mprot, got)
+		t.Fatalf("Excepted protocol %v, got %v", mprot, got)
 	}
 	if got := s.Serialer(); got != mser {
-		t.Fatalf("Excepted Serialer %q, got %q", mser, got)
+		t.Fatalf("Excepted Serialer %v, got %v", mser, got)
 	}
 
 	mser.CloseFn = func() error {
@@ -246,7 +343,7 @@ func TestUnsupportProtocol(t *testing.T) {
 	mprot.InitCmdFn = func() []byte {
 		return nil
 	}
-	if err := s.Init(); err == nil || err != driver.ErrNotSupported {
+	if err := s.Init(); !errors.Is(err, driver.ErrNotSupported) {
 		t.Errorf("Excepted %q\n", driver.ErrNotSupported)
 
 	}
@@ -256,7 +353,7 @@ func TestEncoding(t *testing.T) {
 	s := &Serial{proto: nil}
 	casestr := "Россия"
 	casestr1251 := []byte{0xd0, 0xee, 0xf1, 0xf1, 0xe8, 0xff}
-	if got, err := s.encodetext(casestr); err != nil || got != casestr {
+	if got, err := s.encodetext(context.Background(), casestr); err != nil || got != casestr {
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -264,7 +361,7 @@ func TestEncoding(t *testing.T) {
 	}
 	s.SetEncoding(charmap.Windows1251)
 
-	if got, err := s.encodetext(casestr); err != nil || bytes.Compare([]byte(got), casestr1251) != 0 {
+	if got, err := s.encodetext(context.Background(), casestr); err != nil || bytes.Compare([]byte(got), casestr1251) != 0 {
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -272,6 +369,76 @@ func TestEncoding(t *testing.T) {
 	}
 }
 
+func TestSelectCodeTable(t *testing.T) {
+	mprot := &mockProtocol{}
+	mser := &mockSerialer{}
+	s := &Serial{proto: mprot}
+	s.CreatePort(mser)
+	var sent [][]byte
+	mser.WriteFn = func(b []byte) (int, error) {
+		sent = append(sent, b)
+		return len(b), nil
+	}
+	mprot.CodeTableCmdFn = func(table byte) []byte {
+		return []byte{0x1b, 0x74, table}
+	}
+	mprot.PrintRowCmdFn = func(row byte, text string) []byte {
+		return []byte(text)
+	}
+	mprot.InitCmdFn = func() []byte {
+		return []byte{0x1b, 0x40}
+	}
+
+	// the table is not selected by default
+	s.SetEncoding(charmap.CodePage866)
+	if err := s.PrintRow(1, "Мир"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Errorf("Excepted only the text is sent, got %x", sent)
+	}
+
+	// the protocol does not know the table
+	s.SetSelectCodeTable(true)
+	sent = nil
+	if err := s.PrintRow(1, "Мир"); !errors.Is(err, driver.ErrNotSupported) {
+		t.Errorf("Excepted %q, got %v", driver.ErrNotSupported, err)
+	}
+	if err := s.PrintRow(1, "Мир"); err != nil {
+		t.Errorf("Excepted the error is returned once, got %v", err)
+	}
+
+	s.proto = mockCodeTabler{
+		mockProtocol: mprot,
+		tables:       map[encoding.Encoding]byte{charmap.CodePage866: 17},
+	}
+	s.SetEncoding(charmap.CodePage866)
+	sent = nil
+	if err := s.PrintRow(1, "Мир"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PrintRow(2, "Мир"); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]byte{{0x1b, 0x74, 17}, {0x8c, 0xa8, 0xe0}, {0x8c, 0xa8, 0xe0}}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("Excepted %x, got %x", want, sent)
+	}
+
+	// Init resets the table of the device
+	sent = nil
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PrintRow(1, "Мир"); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]byte{{0x1b, 0x40}, {0x1b, 0x74, 17}, {0x8c, 0xa8, 0xe0}}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("Excepted the table is sent again after Init %x, got %x", want, sent)
+	}
+}
+
 func TestSendSerialer(t *testing.T) {
 	mprot := &mockProtocol{}
 	mser := &mockSerialer{}
@@ -308,10 +475,15 @@ func TestSendSerialer(t *testing.T) {
 	mser.WriteFn = func(b []byte) (int, error) {
 		return 0, nil
 	}
-	msg := fmt.Sprintf("Error write: must %d byte(s), but %d byte(s)", 0, 1)
-	if err := s.Init(); err == nil || err.Error() != msg {
+	msg := fmt.Sprintf("Error write: must %d byte(s), but %d byte(s)", 1, 0)
+	err := s.Init()
+	if err == nil || err.Error() != msg {
 		t.Errorf("Excepted %q, got %q", msg, err)
 	}
+	var short *driver.ShortWriteError
+	if !errors.As(err, &short) || short.Want != 1 || short.Written != 0 || !errors.Is(err, driver.ErrShortWrite) {
+		t.Errorf("Excepted ShortWriteError of 1 byte, got %#v", err)
+	}
 
 }
 func TestSendData(t *testing.T) {
@@ -394,6 +566,12 @@ func TestInvokedProtocol(t *testing.T) {
 		//TODO : Check text
 		return []byte{0x0}
 	}
+	mprot.PrintCmdFn = func(text string) []byte {
+		return []byte(text)
+	}
+	mprot.CharSizeCmdFn = func(width, height byte) []byte {
+		return []byte{0x0}
+	}
 
 	mprot.CursorMoveUpCmdFn = commonFn
 	mprot.CursorMoveDownCmdFn = commonFn
@@ -410,6 +588,31 @@ func TestInvokedProtocol(t *testing.T) {
 		return []byte{0x0}
 	}
 	mprot.FlagsDisableCmdFn = commonFn
+	mprot.SetTimeCmdFn = func(hour, minute byte) []byte {
+		return []byte{0x0}
+	}
+	mprot.ShowClockCmdFn = commonFn
+	mprot.BlinkCmdFn = func(time.Duration) []byte {
+		return []byte{0x0}
+	}
+	mprot.ReverseCmdFn = func(bool) []byte {
+		return []byte{0x0}
+	}
+	mprot.UnderlineCmdFn = func(bool) []byte {
+		return []byte{0x0}
+	}
+	mprot.CodeTableCmdFn = func(byte) []byte {
+		return []byte{0x0}
+	}
+	mprot.CharsetCmdFn = func(byte) []byte {
+		return []byte{0x0}
+	}
+	mprot.DefineCharCmdFn = func(byte, []byte) []byte {
+		return []byte{0x0}
+	}
+	mprot.UserCharsCmdFn = func(bool) []byte {
+		return []byte{0x0}
+	}
 
 	cases := []struct {
 		Name    string
@@ -468,6 +671,16 @@ func TestInvokedProtocol(t *testing.T) {
 			Command: func() error { return s.PrintRow(1, "test") },
 			Invoked: &mprot.PrintRowCmdInvoked,
 		},
+		{
+			Name:    "Print",
+			Command: func() error { return s.Print("test") },
+			Invoked: &mprot.PrintCmdInvoked,
+		},
+		{
+			Name:    "CharSizeCmd",
+			Command: func() error { return s.CharSize(2, 2) },
+			Invoked: &mprot.CharSizeCmdInvoked,
+		},
 
 		{
 			Name:    "CursorMoveUpCmd",
@@ -524,6 +737,51 @@ func TestInvokedProtocol(t *testing.T) {
 			Command: s.FlagsDisable,
 			Invoked: &mprot.FlagsDisableCmdInvoked,
 		},
+		{
+			Name:    "SetTimeCmd",
+			Command: func() error { return s.SetTime(time.Now()) },
+			Invoked: &mprot.SetTimeCmdInvoked,
+		},
+		{
+			Name:    "ShowClockCmd",
+			Command: s.ShowClock,
+			Invoked: &mprot.ShowClockCmdInvoked,
+		},
+		{
+			Name:    "BlinkCmd",
+			Command: func() error { return s.Blink(time.Second) },
+			Invoked: &mprot.BlinkCmdInvoked,
+		},
+		{
+			Name:    "ReverseCmd",
+			Command: func() error { return s.Reverse(true) },
+			Invoked: &mprot.ReverseCmdInvoked,
+		},
+		{
+			Name:    "UnderlineCmd",
+			Command: func() error { return s.Underline(true) },
+			Invoked: &mprot.UnderlineCmdInvoked,
+		},
+		{
+			Name:    "CodeTableCmd",
+			Command: func() error { return s.SetCodeTable(17) },
+			Invoked: &mprot.CodeTableCmdInvoked,
+		},
+		{
+			Name:    "CharsetCmd",
+			Command: func() error { return s.SetCharset(0) },
+			Invoked: &mprot.CharsetCmdInvoked,
+		},
+		{
+			Name:    "DefineCharCmd",
+			Command: func() error { return s.DefineChar(0x80, []byte{1, 2, 3, 4, 5}) },
+			Invoked: &mprot.DefineCharCmdInvoked,
+		},
+		{
+			Name:    "UserCharsCmd",
+			Command: func() error { return s.UserChars(true) },
+			Invoked: &mprot.UserCharsCmdInvoked,
+		},
 	}
 
 	s.CreatePort(mser)
@@ -541,3 +799,37 @@ func TestInvokedProtocol(t *testing.T) {
 
 	}
 }
+
+type mockSizedProtocol struct {
+	*mockProtocol
+}
+
+func (m mockSizedProtocol) Size() (rows, cols byte) {
+	return 2, 20
+}
+
+func TestProtocolError(t *testing.T) {
+	mprot := &mockProtocol{}
+	mprot.ClearCmdFn = func() []byte {
+		return nil
+	}
+	s := MustSerial(mockSizedProtocol{mprot})
+	s.CreatePort(&mockSerialer{})
+
+	err := s.Clear()
+	var perr *driver.ProtocolError
+	if !errors.As(err, &perr) || perr.Command != "Clear" || !errors.Is(err, driver.ErrNotSupported) {
+		t.Errorf("Excepted ProtocolError of Clear, got %#v", err)
+	}
+	if err.Error() != "Clear: Command is not supported" {
+		t.Errorf("Excepted message %q, got %q", "Clear: Command is not supported", err)
+	}
+
+	err = s.PrintRow(3, "text")
+	if !errors.As(err, &perr) || perr.Command != "PrintRow" || !errors.Is(err, driver.ErrInvalidRow) {
+		t.Errorf("Excepted ErrInvalidRow of PrintRow, got %v", err)
+	}
+	if mprot.PrintRowCmdInvoked {
+		t.Error("Excepted PrintRowCmd is not invoked for invalid row")
+	}
+}
@@ -0,0 +1,81 @@
+package com
+
+import (
+	"context"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// DefaultStatusTimeout is the time of waiting for the answer to the status
+// request if the read timeout is not set
+const DefaultStatusTimeout = 500 * time.Millisecond
+
+// IdentifyGap ends the identification answer shorter than its maximal
+// length: no byte comes for the gap after the last one
+const IdentifyGap = 50 * time.Millisecond
+
+// Status requests the status of the device. The device not answering in time
+// is reported as offline. The model and the firmware are requested once
+// after the first answer; the identification is best effort, its failure
+// leaves them empty.
+func (s *Serial) Status() (driver.Status, error) {
+	return s.StatusContext(context.Background())
+}
+func (s *Serial) StatusContext(ctx context.Context) (driver.Status, error) {
+	querier, ok := s.proto.(driver.StatusQuerier)
+	if !ok {
+		return driver.Status{}, &driver.ProtocolError{Command: "Status", Err: driver.ErrNotSupported}
+	}
+	if err := s.mu.LockContext(ctx); err != nil {
+		return driver.Status{}, err
+	}
+	defer s.mu.Unlock()
+	timeout := s.readTimeout
+	if timeout <= 0 {
+		timeout = DefaultStatusTimeout
+	}
+
+	cmd, size := querier.StatusQuery()
+	answer, err := s.query(ctx, "Status", cmd, size, timeout, 0)
+	if _, ok := err.(*TimeoutError); ok {
+		return driver.Status{Raw: answer}, nil
+	}
+	if err != nil {
+		return driver.Status{}, err
+	}
+	status, err := querier.ParseStatus(answer)
+	if err != nil {
+		return status, err
+	}
+	status.Online = true
+
+	if s.ident == nil {
+		s.ident = s.identify(ctx, querier, timeout)
+	}
+	status.Model = s.ident.Model
+	status.Firmware = s.ident.Firmware
+	return status, nil
+}
+
+// identify returns the identification of the device, it is empty
+// if the device is not identified
+func (s *Serial) identify(ctx context.Context, querier driver.StatusQuerier, timeout time.Duration) *driver.Status {
+	ident := &driver.Status{}
+	cmd, size := querier.IdentifyQuery()
+	if len(cmd) == 0 {
+		return ident
+	}
+	answer, err := s.query(ctx, "Identify", cmd, size, timeout, IdentifyGap)
+	if _, ok := err.(*TimeoutError); ok {
+		// the answer has ended before the gap
+		err = nil
+	}
+	if err != nil || len(answer) == 0 {
+		return ident
+	}
+	if err := querier.ParseIdentify(answer, ident); err != nil {
+		return &driver.Status{}
+	}
+	return ident
+}
@@ -0,0 +1,94 @@
+package com
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+type mockStatusProtocol struct {
+	*mockProtocol
+}
+
+func (m mockStatusProtocol) StatusQuery() ([]byte, int) {
+	return []byte{0x10, 0x04, 0x01}, 1
+}
+
+func (m mockStatusProtocol) ParseStatus(answer []byte) (driver.Status, error) {
+	if len(answer) != 1 || answer[0]&0x93 != 0x12 {
+		return driver.Status{}, errors.New("bad status")
+	}
+	return driver.Status{Error: answer[0]&0x08 != 0, Raw: answer}, nil
+}
+
+func (m mockStatusProtocol) IdentifyQuery() ([]byte, int) {
+	return []byte{0x1d, 0x49, 0x41}, 16
+}
+
+func (m mockStatusProtocol) ParseIdentify(answer []byte, status *driver.Status) error {
+	status.Model = "VFD"
+	status.Firmware = string(bytes.TrimRight(answer, "\x00"))
+	return nil
+}
+
+// deviceSerialer answers the requests with the prepared answers
+type deviceSerialer struct {
+	mockSerialer
+	answers map[string][]byte
+	out     chan byte
+}
+
+func newDeviceSerialer(answers map[string][]byte) *deviceSerialer {
+	d := &deviceSerialer{answers: answers, out: make(chan byte, 64)}
+	d.WriteFn = func(b []byte) (int, error) {
+		for _, c := range d.answers[string(b)] {
+			d.out <- c
+		}
+		return len(b), nil
+	}
+	d.ReadFn = func(b []byte) (int, error) {
+		b[0] = <-d.out
+		return 1, nil
+	}
+	return d
+}
+
+func TestStatus(t *testing.T) {
+	dev := newDeviceSerialer(map[string][]byte{
+		"\x10\x04\x01": {0x1a},
+		"\x1d\x49\x41": []byte("1.02\x00"),
+	})
+	s := MustSerial(mockStatusProtocol{&mockProtocol{}})
+	s.CreatePort(dev)
+	s.SetTimeouts(time.Second, 0)
+
+	start := time.Now()
+	status, err := s.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Online || !status.Error || status.Model != "VFD" || status.Firmware != "1.02" {
+		t.Errorf("Excepted online VFD 1.02 with error, got %+v", status)
+	}
+	// the identification shorter than the maximum ends after the gap
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Excepted the identification in %v, got %v", IdentifyGap, elapsed)
+	}
+
+	// the device is silent
+	s.SetTimeouts(20*time.Millisecond, 0)
+	dev.answers = nil
+	status, err = s.Status()
+	if err != nil || status.Online {
+		t.Errorf("Excepted offline, got %+v, %v", status, err)
+	}
+
+	s = MustSerial(&mockProtocol{})
+	s.CreatePort(dev)
+	if _, err := s.Status(); !errors.Is(err, driver.ErrNotSupported) {
+		t.Errorf("Excepted %v, got %v", driver.ErrNotSupported, err)
+	}
+}
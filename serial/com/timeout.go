@@ -0,0 +1,100 @@
+package com
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+// TimeoutError is returned when the device does not accept the data
+// or does not answer in time
+type TimeoutError struct {
+	Op    string
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("com: %s timeout after %v", e.Op, e.After)
+}
+
+// Timeout reports that the error is a timeout
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return driver.ErrTimeout
+}
+
+// SetTimeouts sets the time limits of a write and a receive.
+// Zero means no limit.
+func (s *Serial) SetTimeouts(read, write time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readTimeout = read
+	s.writeTimeout = write
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError replaces the error of the expired timeout of the operation
+// with the TimeoutError. The errors of the parent context are returned as is.
+func timeoutError(parent context.Context, err error, op string, timeout time.Duration) error {
+	if err == context.DeadlineExceeded && parent.Err() == nil {
+		return &TimeoutError{Op: op, After: timeout}
+	}
+	return err
+}
+
+// Query writes the command and reads the response of up to maxLen bytes
+// holding the device for both. A zero timeout means the read timeout
+// set by SetTimeouts. If the response is shorter than maxLen, the bytes
+// received are returned with the TimeoutError.
+func (s *Serial) Query(cmd []byte, maxLen int, timeout time.Duration) ([]byte, error) {
+	return s.QueryContext(context.Background(), cmd, maxLen, timeout)
+}
+func (s *Serial) QueryContext(ctx context.Context, cmd []byte, maxLen int, timeout time.Duration) ([]byte, error) {
+	if err := s.mu.LockContext(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.Unlock()
+	return s.query(ctx, "Query", cmd, maxLen, timeout, 0)
+}
+
+// query sends the request and receives the answer, the name is reported
+// to the observers. If the gap is set, the answer ends when no byte comes
+// for the gap.
+func (s *Serial) query(ctx context.Context, name string, cmd []byte, maxLen int, timeout, gap time.Duration) ([]byte, error) {
+	s.discard()
+	if err := s.sendCommand(ctx, name, cmd); err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = s.readTimeout
+	}
+	b := make([]byte, maxLen)
+	n, err := s.receiveCommand(ctx, name, b, timeout, gap)
+	return b[:n], err
+}
+
+// discard drops the data received before the request, e.g. the late answer
+// of the previous request. The read still waiting for the data is kept:
+// the device has sent nothing yet, so it gets the answer.
+func (s *Serial) discard() {
+	s.unread = nil
+	if s.reading == nil {
+		return
+	}
+	select {
+	case <-s.reading:
+		s.reading = nil
+	default:
+	}
+}
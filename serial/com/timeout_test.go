@@ -0,0 +1,153 @@
+package com
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/arteev/gold/driver"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		<-release
+		return len(b), nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+	s.SetTimeouts(0, 10*time.Millisecond)
+
+	err := s.Send([]byte{1})
+	terr, ok := err.(*TimeoutError)
+	if !ok || terr.Op != "write" || !terr.Timeout() || !errors.Is(err, driver.ErrTimeout) {
+		t.Fatalf("Excepted write timeout, got %v", err)
+	}
+	if got := err.Error(); got != "com: write timeout after 10ms" {
+		t.Errorf("Excepted message %q, got %q", "com: write timeout after 10ms", got)
+	}
+
+	// the deadline of the caller is not the timeout of the device
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := s.SendContext(ctx, []byte{1}); err != context.DeadlineExceeded {
+		t.Errorf("Excepted %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestReceiveTimeout(t *testing.T) {
+	data := make(chan byte, 2)
+	mser := &mockSerialer{}
+	mser.ReadFn = func(b []byte) (int, error) {
+		b[0] = <-data
+		return 1, nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+	s.SetTimeouts(10*time.Millisecond, 0)
+
+	data <- 1
+	buf := make([]byte, 2)
+	n, err := s.Receive(buf)
+	if terr, ok := err.(*TimeoutError); !ok || terr.Op != "read" || n != 1 {
+		t.Fatalf("Excepted 1 byte and read timeout, got %d byte(s) and %v", n, err)
+	}
+	data <- 2
+	data <- 3
+	n, err = s.Receive(buf)
+	if err != nil || !bytes.Equal(buf[:n], []byte{2, 3}) {
+		t.Errorf("Excepted [2 3], got %v (%v)", buf[:n], err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var written []byte
+	response := make(chan byte, 4)
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		written = append(written, b...)
+		for _, c := range []byte{0x06, 'O', 'K'} {
+			response <- c
+		}
+		return len(b), nil
+	}
+	mser.ReadFn = func(b []byte) (int, error) {
+		b[0] = <-response
+		return 1, nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+
+	got, err := s.Query([]byte{0x1b, 0x76}, 3, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, []byte{0x06, 'O', 'K'}) || !bytes.Equal(written, []byte{0x1b, 0x76}) {
+		t.Errorf("Excepted response [6 79 75] to [27 118], got %v to %v", got, written)
+	}
+
+	got, err = s.Query([]byte{0x1b, 0x76}, 4, 10*time.Millisecond)
+	if _, ok := err.(*TimeoutError); !ok || len(got) != 3 {
+		t.Errorf("Excepted 3 bytes and timeout, got %v and %v", got, err)
+	}
+}
+
+func TestQueryDiscardsStale(t *testing.T) {
+	data := make(chan []byte, 4)
+	mser := &mockSerialer{}
+	mser.WriteFn = func(b []byte) (int, error) {
+		data <- []byte{0x06}
+		return len(b), nil
+	}
+	mser.ReadFn = func(b []byte) (int, error) {
+		return copy(b, <-data), nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+	s.SetTimeouts(10*time.Millisecond, 0)
+
+	// the bytes left by the previous receive
+	data <- []byte{0x15, 0x15}
+	if _, err := s.Receive(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Query([]byte{0x05}, 1, time.Second)
+	if err != nil || !bytes.Equal(got, []byte{0x06}) {
+		t.Errorf("Excepted [6], got %v (%v)", got, err)
+	}
+
+	// the late answer of the timed out receive
+	if _, err := s.Receive(make([]byte, 1)); !errors.Is(err, driver.ErrTimeout) {
+		t.Fatalf("Excepted timeout, got %v", err)
+	}
+	data <- []byte{0x15}
+	for len(data) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	got, err = s.Query([]byte{0x05}, 1, time.Second)
+	if err != nil || !bytes.Equal(got, []byte{0x06}) {
+		t.Errorf("Excepted [6], got %v (%v)", got, err)
+	}
+}
+
+func TestReceiveNoData(t *testing.T) {
+	mser := &mockSerialer{}
+	mser.ReadFn = func(b []byte) (int, error) {
+		return 0, nil
+	}
+	s := MustSerial(&mockProtocol{})
+	s.CreatePort(mser)
+	if _, err := s.Receive(make([]byte, 1)); err != io.ErrNoProgress {
+		t.Errorf("Excepted %v, got %v", io.ErrNoProgress, err)
+	}
+	s.SetTimeouts(20*time.Millisecond, 0)
+	if _, err := s.Receive(make([]byte, 1)); !errors.Is(err, driver.ErrTimeout) {
+		t.Errorf("Excepted timeout, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package com
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// translit are the Latin transliterations of the runes without a base letter
+var translit = map[rune]string{
+	'ß': "ss", 'ẞ': "SS",
+	'Æ': "AE", 'æ': "ae",
+	'Œ': "OE", 'œ': "oe",
+	'Ø': "O", 'ø': "o",
+	'Ł': "L", 'ł': "l",
+	'Đ': "D", 'đ': "d",
+	'Þ': "Th", 'þ': "th",
+	'ı': "i",
+
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "E",
+	'Ж': "Zh", 'З': "Z", 'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M",
+	'Н': "N", 'О': "O", 'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U",
+	'Ф': "F", 'Х': "Kh", 'Ц': "Ts", 'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch",
+	'Ъ': "'", 'Ы': "Y", 'Ь': "'", 'Э': "E", 'Ю': "Yu", 'Я': "Ya",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "'", 'ы': "y", 'ь': "'", 'э': "e", 'ю': "yu", 'я': "ya",
+	'Є': "Ye", 'є': "ye", 'І': "I", 'і': "i", 'Ї': "Yi", 'ї': "yi",
+	'Ґ': "G", 'ґ': "g", 'Ў': "U", 'ў': "u",
+
+	'€': "EUR", '₽': "RUB", '£': "GBP", '¥': "JPY", '₴': "UAH", '₸': "KZT",
+	'№': "No", '©': "(C)", '®': "(R)", '™': "TM", '°': "o",
+	'«': "\"", '»': "\"", '„': "\"", '“': "\"", '”': "\"",
+	'‘': "'", '’': "'", '‚': ",",
+	'–': "-", '—': "-", '−': "-", '…': "...", '×': "x", '·': ".",
+	'\u00a0': " ",
+}
+
+// transliterate returns the rune written with ASCII or an empty string
+func transliterate(r rune) string {
+	if t, ok := translit[r]; ok {
+		return t
+	}
+	var result []rune
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		if t, ok := translit[d]; ok {
+			result = append(result, []rune(t)...)
+			continue
+		}
+		if d > unicode.MaxASCII {
+			return ""
+		}
+		result = append(result, d)
+	}
+	return string(result)
+}
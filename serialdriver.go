@@ -1,8 +1,12 @@
 package serial
 
 import (
+	"io"
+	"time"
+
 	"github.com/arteev/gold/display"
 	"github.com/arteev/gold/driver"
+	"github.com/arteev/gold/record"
 	"github.com/arteev/gold/serial/com"
 	"github.com/tarm/serial"
 )
@@ -25,10 +29,34 @@ func (d *SerialDriver) GetDisplay(protocol driver.Protocol, config map[string]in
 	c.Size = get("Size", byte(serial.DefaultSize)).(byte)
 	c.StopBits = serial.StopBits(get("StopBits", byte(serial.Stop1)).(byte))
 	c.Parity = serial.Parity(get("Parity", byte(serial.ParityNone)).(byte))
+	policy, err := com.ParseUnencodable(get("Unencodable", "replace").(string))
+	if err != nil {
+		return nil, err
+	}
+	s.SetUnencodable(policy, get("Substitute", com.DefaultSubstitute).(string))
+	s.SetGlyphSlots(get("GlyphSlots", []byte(nil)).([]byte)...)
+	s.SetBidi(get("Bidi", false).(bool))
+	s.SetSelectCodeTable(get("SelectCodeTable", false).(bool))
+	s.SetTimeouts(get("ReadTimeout", time.Duration(0)).(time.Duration),
+		get("WriteTimeout", time.Duration(0)).(time.Duration))
+	timing := s.Timing()
+	timing.AfterInit = get("AfterInit", timing.AfterInit).(time.Duration)
+	timing.AfterClear = get("AfterClear", timing.AfterClear).(time.Duration)
+	timing.AfterBrightness = get("AfterBrightness", timing.AfterBrightness).(time.Duration)
+	timing.BytesPerSecond = get("BytesPerSecond", timing.BytesPerSecond).(int)
+	s.SetTiming(timing)
 	port, err := serial.OpenPort(c)
 	if err != nil {
 		return nil, err
 	}
+	if w, ok := get("Record", nil).(io.Writer); ok {
+		recorder := record.New(port, w)
+		if decodable, ok := protocol.(driver.Decodable); ok {
+			recorder.SetDecoder(decodable.Decoder())
+		}
+		s.CreatePort(recorder)
+		return s, nil
+	}
 	s.CreatePort(port)
 	return s, nil
 }